@@ -0,0 +1,53 @@
+package cert
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// cefSeverities maps the level of a finding to a CEF severity.
+var cefSeverities = map[string]int{
+	"error":   8,
+	"warning": 5,
+	"ignore":  0,
+}
+
+// CEF returns the findings of certs, as reported by SARIF, as ArcSight
+// Common Event Format events, one per line.
+func (certs Certs) CEF() string {
+	names := map[string]string{}
+	for _, r := range sarifRules {
+		names[r.id] = strings.TrimSuffix(r.text, ".")
+	}
+
+	var b strings.Builder
+	for _, c := range certs {
+		for _, f := range findings(c) {
+			name, ok := names[f.Rule]
+			if !ok {
+				name = f.Rule
+			}
+			fmt.Fprintf(&b, "CEF:0|genkiroid|cert|%d|%s|%s|%d|dhost=%s msg=%s",
+				SchemaVersion,
+				cefHeaderEscaper.Replace(f.Rule),
+				cefHeaderEscaper.Replace(name),
+				cefSeverities[f.Severity],
+				cefExtensionEscaper.Replace(c.DomainName),
+				cefExtensionEscaper.Replace(f.Message),
+			)
+			if c.Issuer != "" {
+				fmt.Fprintf(&b, " cs1Label=issuer cs1=%s", cefExtensionEscaper.Replace(c.Issuer))
+			}
+			if c.NotAfter != "" {
+				fmt.Fprintf(&b, " cs2Label=notAfter cs2=%s", cefExtensionEscaper.Replace(c.NotAfter))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
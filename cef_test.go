@@ -0,0 +1,19 @@
+package cert
+
+import (
+	"testing"
+)
+
+func TestCertsCEF(t *testing.T) {
+	certs := Certs{
+		{DomainName: "ok.example.com", Status: StatusOK},
+		{DomainName: "expired.example.com", Issuer: "CA=1", Status: StatusExpired, NotAfter: "2017-06-01 00:00:00 +0000 UTC"},
+		{DomainName: "down.example.com", Status: StatusError, Error: "dial tcp: connection refused", ErrorKind: ErrorKindRefused},
+	}
+	expected := `CEF:0|genkiroid|cert|1|expired|Certificate has expired|8|dhost=expired.example.com msg=Certificate expired at 2017-06-01 00:00:00 +0000 UTC. cs1Label=issuer cs1=CA\=1 cs2Label=notAfter cs2=2017-06-01 00:00:00 +0000 UTC
+CEF:0|genkiroid|cert|1|unreachable|Host could not be reached|8|dhost=down.example.com msg=dial tcp: connection refused
+`
+	if got := certs.CEF(); got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+}
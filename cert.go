@@ -1,37 +1,48 @@
 package cert
 
 import (
-	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/url"
+	"strconv"
 	"strings"
-	"text/template"
 	"time"
 )
 
-const defaultTempl = `{{range .}}DomainName: {{.DomainName}}
-IP:         {{.IP}}
-Issuer:     {{.Issuer}}
-NotBefore:  {{.NotBefore}}
-NotAfter:   {{.NotAfter}}
-CommonName: {{.CommonName}}
-SANs:       {{.SANs}}
-Error:      {{.Error}}
+const defaultTempl = `{{range .}}{{label "DomainName"}}{{hostport .}}
+{{label "IP"}}{{.IP}}
+{{label "Issuer"}}{{trunc . .Issuer}}
+{{label "NotBefore"}}{{.NotBefore}}
+{{label "NotAfter"}}{{.NotAfter}}
+{{label "CommonName"}}{{trunc . .CommonName}}
+{{label "SANs"}}{{trunc . (print (sans .))}}
+{{label "Error"}}{{trunc . .Error}}
+{{if .Warnings}}{{label "Warnings"}}{{trunc . (join "; " .Warnings)}}
+{{end}}{{if .Pin}}{{label "Pin"}}{{.Pin}}
+{{end}}{{label "Status"}}{{translate .Status}}
 
 {{end}}
 `
 
-const markdownTempl = `DomainName | IP | Issuer | NotBefore | NotAfter | CN | SANs | Error
---- | --- | --- | --- | --- | --- | --- | ---
-{{range .}}{{.DomainName}} | {{.IP}} | {{.Issuer}} | {{.NotBefore}} | {{.NotAfter}} | {{.CommonName}} | {{range .SANs}}{{.}}<br/>{{end}} | {{.Error}}
+const markdownTempl = `DomainName | IP | Issuer | NotBefore | NotAfter | CN | SANs | Error | Status
+--- | --- | --- | --- | --- | --- | --- | --- | ---
+{{range $c := .}}{{hostport .}} | {{.IP}} | {{trunc . .Issuer}} | {{.NotBefore}} | {{.NotAfter}} | {{trunc . .CommonName}} | {{range sans .}}{{trunc $c .}}<br/>{{end}} | {{trunc . .Error}} | {{translate .Status}}
 {{end}}
 `
 
 const defaultPort = "443"
 
+const (
+	StatusOK       = "OK"
+	StatusExpiring = "EXPIRING"
+	StatusExpired  = "EXPIRED"
+	StatusError    = "ERROR"
+)
+
 type Certs []*Cert
 
 type Cert struct {
@@ -40,28 +51,240 @@ type Cert struct {
 	Issuer     string   `json:"issuer"`
 	CommonName string   `json:"commonName"`
 	SANs       []string `json:"sans"`
+	SANCount   int      `json:"sanCount"`
 	NotBefore  string   `json:"notBefore"`
 	NotAfter   string   `json:"notAfter"`
 	Error      string   `json:"error"`
+	Status     string   `json:"status"`
+	ErrorKind  string   `json:"errorKind,omitempty"`
+
+	// Warnings describe problems that do not make the certificate fail,
+	// such as an upcoming expiry or an incomplete chain.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// UnicodeName is the name given for an internationalized DomainName,
+	// which holds its xn-- form.
+	UnicodeName string `json:"unicodeName,omitempty"`
+
+	// Port is the port connected to and Input the target as it was given,
+	// e.g. "https://example.com:8443/".
+	Port  string `json:"port,omitempty"`
+	Input string `json:"input,omitempty"`
+	// Scheme is the URL scheme of Input, e.g. "https" or "smtp", or empty
+	// if Input is not a URL.
+	Scheme string `json:"scheme,omitempty"`
+
+	ConnectTime   time.Duration `json:"connectTime,omitempty"`
+	DNSTime       time.Duration `json:"dnsTime,omitempty"`
+	TCPTime       time.Duration `json:"tcpTime,omitempty"`
+	HandshakeTime time.Duration `json:"handshakeTime,omitempty"`
+
+	ChainSize int `json:"chainSize,omitempty"`
+	// ChainNotAfter is the earliest NotAfter of the presented chain, when
+	// the chain stops working even if the leaf is still valid.
+	ChainNotAfter string `json:"chainNotAfter,omitempty"`
+
+	OCSPServers []string `json:"ocspServers,omitempty"`
+	IssuerURLs  []string `json:"issuerURLs,omitempty"`
+	X509Version int      `json:"x509Version,omitempty"`
+	IsCA        bool     `json:"isCA,omitempty"`
+	MaxPathLen  *int     `json:"maxPathLen,omitempty"`
+
+	LeafSignatureValid *bool       `json:"leafSignatureValid,omitempty"`
+	ChainPaths         []ChainPath `json:"chainPaths,omitempty"`
+	ChainIssues        []string    `json:"chainIssues,omitempty"`
+
+	KeyExchange string `json:"keyExchange,omitempty"`
+	PostQuantum bool   `json:"postQuantum,omitempty"`
+	JA3S        string `json:"ja3s,omitempty"`
+	HSTS        *HSTS  `json:"hsts,omitempty"`
+
+	StartTLS string `json:"startTLS,omitempty"`
+
+	// PTR are the reverse DNS names of IP, see Options.PTR.
+	PTR []string `json:"ptr,omitempty"`
+	// IPInfo is the AS and country of IP, see Options.IPInfo.
+	IPInfo *IPInfo `json:"ipInfo,omitempty"`
+
+	// NoSNI is the certificate returned without SNI, see
+	// Options.CompareNoSNI.
+	NoSNI *SNIProbe `json:"noSNI,omitempty"`
+	// SNIProbes are the certificates returned for Options.ServerNames.
+	SNIProbes []SNIProbe `json:"sniProbes,omitempty"`
+
+	// Pin is PinPass or PinFail if Options.Pins has pins for the host.
+	Pin string `json:"pin,omitempty"`
+
+	// KeyMatch reports whether the leaf matches Options.PrivateKey.
+	KeyMatch *bool `json:"keyMatch,omitempty"`
+
+	// CSRDiffs are the differences of the leaf from Options.CSR.
+	CSRDiffs []CSRDiff `json:"csrDiffs,omitempty"`
+
+	// SCTs are the signed certificate timestamps embedded in the leaf,
+	// verified against Options.CTLogs.
+	SCTs []SCT `json:"scts,omitempty"`
+
+	// SSHHostKey is the key of the SSH server on port 22 of the host, see
+	// Options.SSH.
+	SSHHostKey *HostKey `json:"sshHostKey,omitempty"`
+
+	// DowngradeProtection is the result of Options.CheckFallbackSCSV.
+	DowngradeProtection string `json:"downgradeProtection,omitempty"`
+
+	Redirects []string `json:"redirects,omitempty"`
+	Via       string   `json:"via,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Findings are those of Options.Checkers.
+	Findings []Finding `json:"findings,omitempty"`
+	// Policy holds the severities Options.Policy sets for the findings of
+	// this certificate.
+	Policy map[string]string `json:"policy,omitempty"`
+
+	Revocation      string `json:"revocation,omitempty"`
+	RevocationError string `json:"revocationError,omitempty"`
+
+	Extensions []Extension `json:"extensions,omitempty"`
+
+	Debug *Diagnostic `json:"debug,omitempty"`
+
+	chain       []*x509.Certificate
+	notAfter    time.Time
+	maxSANs     int
+	unicodeSANs bool
+	maxWidth    int
 }
 
-var tokens = make(chan struct{}, 128)
+// defaultConcurrency is the number of targets scanned at once if
+// Options.Concurrency is zero.
+const defaultConcurrency = 128
+
+var tokens = make(chan struct{}, defaultConcurrency)
 
+// SkipVerify is the default of Options.InsecureSkipVerify.
+//
+// Deprecated: SkipVerify is shared by every caller in the process.
+// Pass Options to NewCertWithOptions or NewCertsWithOptions instead.
 var SkipVerify = false
 
-var serverCert = func(host, port string) (*x509.Certificate, string, error) {
-	conn, err := tls.Dial("tcp", host+":"+port, &tls.Config{
-		InsecureSkipVerify: SkipVerify,
-	})
+var ExpiringThreshold = 30 * 24 * time.Hour
+
+var now = time.Now
+
+type serverInfo struct {
+	chain         []*x509.Certificate
+	ip            string
+	connectTime   time.Duration
+	dnsTime       time.Duration
+	tcpTime       time.Duration
+	handshakeTime time.Duration
+	diagnostic    *Diagnostic
+	curve         tls.CurveID
+	ja3s          string
+	hsts          *HSTS
+	startTLS      string
+	version       uint16
+}
+
+var serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+	startTLS, err := opts.startTLS(port)
 	if err != nil {
-		return &x509.Certificate{}, "", err
+		return nil, err
+	}
+	var proxy *url.URL
+	if opts.Proxy != nil {
+		if proxy, err = opts.Proxy(host); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	var rawConn net.Conn
+	var tcpStart time.Time
+	if proxy != nil {
+		tcpStart = start
+		_, span := startSpan(ctx, opts, "cert.dial", "host", host, "port", port)
+		conn, err := dialProxy(ctx, proxy, net.JoinHostPort(host, port), opts)
+		span.End(err)
+		if err != nil {
+			return nil, err
+		}
+		rawConn = conn
+	} else {
+		_, span := startSpan(ctx, opts, "cert.resolve", "host", host)
+		addrs, err := opts.resolver().LookupIPAddr(ctx, host)
+		span.End(err)
+		if err != nil {
+			opts.logDebug("lookup failed", "host", host, "err", err)
+			return nil, err
+		}
+		opts.logDebug("lookup done", "host", host, "addrs", len(addrs), "elapsed", time.Since(start))
+		tcpStart = time.Now()
+		_, span = startSpan(ctx, opts, "cert.dial", "host", host, "port", port)
+		conn, err := dialAny(ctx, addrs, port, opts)
+		span.End(err)
+		if err != nil {
+			return nil, err
+		}
+		rawConn = conn
+	}
+	if startTLS != "" {
+		if err := negotiateStartTLS(ctx, rawConn, startTLS, opts); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+	}
+	var rec *recordingConn
+	if opts.debug() || opts.JA3S {
+		rec = &recordingConn{Conn: rawConn}
+		rawConn = rec
 	}
+	handshakeStart := time.Now()
+	conn := tls.Client(rawConn, opts.tlsConfig(host))
 	defer conn.Close()
-	addr := conn.RemoteAddr()
-	ip, _, _ := net.SplitHostPort(addr.String())
-	cert := conn.ConnectionState().PeerCertificates[0]
+	_, span := startSpan(ctx, opts, "cert.handshake", "host", host, "port", port)
+	err = conn.HandshakeContext(ctx)
+	span.End(err)
+	if err != nil {
+		opts.logDebug("handshake failed", "host", host, "port", port, "err", err)
+		return nil, err
+	}
+	end := time.Now()
+	opts.logDebug("handshake done", "host", host, "port", port, "elapsed", end.Sub(handshakeStart))
 
-	return cert, ip, nil
+	var ip string
+	if proxy == nil {
+		ip, _, _ = net.SplitHostPort(conn.RemoteAddr().String())
+	}
+
+	var diag *Diagnostic
+	var ja3s string
+	if rec != nil && opts.debug() {
+		diag = rec.diagnostic(conn.ConnectionState())
+	}
+	if rec != nil && opts.JA3S {
+		ja3s = rec.ja3s()
+	}
+	var hsts *HSTS
+	if opts.HSTS && startTLS == "" {
+		hsts = probeHSTS(ctx, conn, opts.tlsConfig(host).ServerName, port, opts.Timeout)
+	}
+
+	return &serverInfo{
+		chain:         conn.ConnectionState().PeerCertificates,
+		ip:            ip,
+		connectTime:   end.Sub(start),
+		dnsTime:       tcpStart.Sub(start),
+		tcpTime:       handshakeStart.Sub(tcpStart),
+		handshakeTime: end.Sub(handshakeStart),
+		diagnostic:    diag,
+		curve:         conn.ConnectionState().CurveID,
+		ja3s:          ja3s,
+		hsts:          hsts,
+		startTLS:      startTLS,
+		version:       conn.ConnectionState().Version,
+	}, nil
 }
 
 func validate(s []string) error {
@@ -87,70 +310,454 @@ func SplitHostPort(hostport string) (string, string, error) {
 	return host, port, nil
 }
 
+// schemes are the URL schemes accepted as targets, with their default port
+// and STARTTLS protocol, StartTLSNone for implicit TLS.
+var schemes = map[string]struct{ port, startTLS string }{
+	"https":      {"443", StartTLSNone},
+	"smtp":       {"25", "smtp"},
+	"smtps":      {"465", StartTLSNone},
+	"imap":       {"143", "imap"},
+	"imaps":      {"993", StartTLSNone},
+	"pop3":       {"110", "pop3"},
+	"pop3s":      {"995", StartTLSNone},
+	"ldap":       {"389", "ldap"},
+	"ldaps":      {"636", StartTLSNone},
+	"postgres":   {"5432", "postgres"},
+	"postgresql": {"5432", "postgres"},
+}
+
+// targetScheme returns the lower case URL scheme of target, or "" if it is
+// not a URL.
+func targetScheme(target string) string {
+	i := strings.Index(target, "://")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(target[:i])
+}
+
+// splitTarget accepts host, host:port or a URL of one of schemes. Service
+// names such as imaps are accepted as port and returned as their number.
+func splitTarget(target string) (string, string, error) {
+	if !strings.Contains(target, "://") {
+		host, port, err := SplitHostPort(target)
+		if err != nil {
+			return host, port, err
+		}
+		port, err = lookupPort(port)
+		return host, port, err
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", "", err
+	}
+	scheme, ok := schemes[u.Scheme]
+	if !ok {
+		return u.Hostname(), "", fmt.Errorf("Unsupported scheme %q.", u.Scheme)
+	}
+	port := u.Port()
+	if port == "" {
+		port = scheme.port
+	}
+	return u.Hostname(), port, nil
+}
+
+// lookupPort returns the number of the TCP service port.
+func lookupPort(port string) (string, error) {
+	if _, err := strconv.Atoi(port); err == nil {
+		return port, nil
+	}
+	n, err := net.LookupPort("tcp", port)
+	if err != nil {
+		return "", fmt.Errorf("Unknown service %q.", port)
+	}
+	return strconv.Itoa(n), nil
+}
+
 func NewCert(hostport string) *Cert {
-	host, port, err := SplitHostPort(hostport)
+	return NewCertWithOptions(hostport, nil)
+}
+
+func NewCertWithOptions(hostport string, opts *Options) *Cert {
+	return newCertContext(context.Background(), hostport, opts.orDefault())
+}
+
+func newCertContext(ctx context.Context, hostport string, opts *Options) *Cert {
+	c := fetchCert(ctx, hostport, opts)
+	c.Input = hostport
+	c.setDisplay(opts)
+	return c
+}
+
+func fetchCert(ctx context.Context, hostport string, opts *Options) *Cert {
+	host, port, err := splitTarget(hostport)
 	if err != nil {
-		return &Cert{DomainName: host, Error: err.Error()}
+		return errorCert(host, err)
+	}
+	scheme := targetScheme(hostport)
+	if s, ok := schemes[scheme]; ok && opts.StartTLS == "" {
+		o := *opts
+		o.StartTLS = s.startTLS
+		opts = &o
+	}
+	name := host
+	if host, err = toASCII(host); err != nil {
+		c := errorCert(name, err)
+		c.Port = port
+		c.Scheme = scheme
+		return c
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
-	cert, ip, err := serverCert(host, port)
+	ctx, span := startSpan(ctx, opts, "cert.target", "host", host, "port", port)
+	info, err := serverCert(ctx, host, port, opts)
+	span.End(err)
+	var c *Cert
 	if err != nil {
-		return &Cert{DomainName: host, Error: err.Error()}
+		c = errorCert(host, err)
+	} else {
+		c = newCert(host, info, opts)
+		if opts.CheckRevocation && c.Error == "" {
+			checkRevocation(ctx, c, info.chain, opts)
+		}
+		if opts.PTR && c.IP != "" {
+			c.PTR = lookupPTR(ctx, opts, c.IP)
+		}
+		if opts.IPInfo != nil && c.IP != "" {
+			c.IPInfo = lookupIPInfo(ctx, opts, opts.IPInfo, c.IP)
+		}
+		if opts.CompareNoSNI && len(info.chain) > 0 {
+			p := probeSNI(ctx, host, info.ip, port, "", info.chain[0], opts)
+			c.NoSNI = &p
+		}
+		if len(opts.ServerNames) > 0 && len(info.chain) > 0 {
+			c.SNIProbes = probeServerNames(ctx, host, info.ip, port, opts.ServerNames, info.chain[0], opts)
+		}
+		if opts.CheckFallbackSCSV && c.Error == "" {
+			c.DowngradeProtection = checkFallbackSCSV(ctx, host, info.ip, port, info.startTLS, info.version, opts)
+		}
+		if pins, ok := opts.Pins[host]; ok {
+			c.Pin = checkPins(info.chain, pins)
+		} else if pins, ok := opts.Pins[name]; ok {
+			c.Pin = checkPins(info.chain, pins)
+		}
+		if opts.PrivateKey != nil && len(info.chain) > 0 {
+			match := keyMatches(info.chain[0], opts.PrivateKey)
+			c.KeyMatch = &match
+		}
+		if opts.CSR != nil && len(info.chain) > 0 {
+			c.CSRDiffs = CompareCSR(opts.CSR, info.chain[0])
+		}
+		runCheckers(c, opts.Checkers)
 	}
-	return &Cert{
+	if opts.SSH {
+		c.SSHHostKey = newHostKey(ctx, host, defaultSSHPort, opts)
+	}
+	c.Port = port
+	c.Scheme = scheme
+	if opts.Policy != nil {
+		c.Policy = opts.Policy.severities(c.DomainName)
+	}
+	if c.Error == "" {
+		c.Warnings = warnings(c)
+	}
+	if name != host {
+		c.UnicodeName = name
+	}
+	return c
+}
+
+func newCert(host string, info *serverInfo, opts *Options) *Cert {
+	if len(info.chain) == 0 {
+		return errorCert(host, errNoPeerCertificates)
+	}
+	cert := info.chain[0]
+	c := &Cert{
 		DomainName: host,
-		IP:         ip,
+		IP:         info.ip,
 		Issuer:     cert.Issuer.CommonName,
 		CommonName: cert.Subject.CommonName,
 		SANs:       cert.DNSNames,
+		SANCount:   len(cert.DNSNames),
 		NotBefore:  cert.NotBefore.In(time.Local).String(),
 		NotAfter:   cert.NotAfter.In(time.Local).String(),
 		Error:      "",
+		Status:     status(cert.NotAfter, opts.expiringThreshold()),
+
+		ConnectTime:   info.connectTime,
+		DNSTime:       info.dnsTime,
+		TCPTime:       info.tcpTime,
+		HandshakeTime: info.handshakeTime,
+
+		ChainSize:     chainSize(info.chain),
+		ChainNotAfter: chainNotAfter(info.chain).In(time.Local).String(),
+
+		OCSPServers: cert.OCSPServer,
+		IssuerURLs:  cert.IssuingCertificateURL,
+		X509Version: cert.Version,
+		IsCA:        cert.BasicConstraintsValid && cert.IsCA,
+		MaxPathLen:  maxPathLen(cert),
+
+		LeafSignatureValid: leafSignatureValid(info.chain),
+		ChainIssues:        chainIssues(info.chain),
+
+		Debug: info.diagnostic,
+
+		chain:    info.chain,
+		notAfter: cert.NotAfter,
+	}
+	c.KeyExchange, c.PostQuantum = keyExchange(info.curve)
+	c.JA3S = info.ja3s
+	c.HSTS = info.hsts
+	c.StartTLS = info.startTLS
+	c.SCTs = scts(info.chain, opts)
+	if opts.Extensions {
+		c.Extensions = extensions(cert)
+	}
+	if opts.ChainPaths {
+		c.ChainPaths = chainPaths(info.chain, opts.tlsConfig(host).RootCAs)
+	}
+	return c
+}
+
+// hostport returns the domain name of c followed by its port unless that
+// is the default port, and preceded by the scheme if there is one.
+func hostport(c *Cert) string {
+	s := c.DomainName
+	port := defaultPort
+	if scheme, ok := schemes[c.Scheme]; ok {
+		port = scheme.port
+	}
+	if c.Port != "" && c.Port != port {
+		s = net.JoinHostPort(c.DomainName, c.Port)
 	}
+	if c.Scheme != "" {
+		s = c.Scheme + "://" + s
+	}
+	return s
+}
+
+func errorCert(host string, err error) *Cert {
+	return &Cert{
+		DomainName: host,
+		Error:      err.Error(),
+		Status:     StatusError,
+		ErrorKind:  errorKind(err),
+	}
+}
+
+func status(notAfter time.Time, threshold time.Duration) string {
+	switch {
+	case now().After(notAfter):
+		return StatusExpired
+	case now().Add(threshold).After(notAfter):
+		return StatusExpiring
+	}
+	return StatusOK
+}
+
+func maxPathLen(cert *x509.Certificate) *int {
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return nil
+	}
+	if cert.MaxPathLen <= 0 && !cert.MaxPathLenZero {
+		return nil
+	}
+	n := cert.MaxPathLen
+	return &n
+}
+
+// leafSignatureValid reports whether the leaf is signed by the next
+// certificate presented by the server, or nil if there is none.
+func leafSignatureValid(chain []*x509.Certificate) *bool {
+	if len(chain) < 2 {
+		return nil
+	}
+	valid := chain[0].CheckSignatureFrom(chain[1]) == nil
+	return &valid
+}
+
+// chainNotAfter returns the earliest NotAfter of chain.
+func chainNotAfter(chain []*x509.Certificate) time.Time {
+	notAfter := chain[0].NotAfter
+	for _, c := range chain[1:] {
+		if c.NotAfter.Before(notAfter) {
+			notAfter = c.NotAfter
+		}
+	}
+	return notAfter
+}
+
+func chainSize(chain []*x509.Certificate) int {
+	size := 0
+	for _, c := range chain {
+		size += len(c.Raw)
+	}
+	return size
 }
 
 func NewCerts(s []string) (Certs, error) {
+	return NewCertsWithOptions(s, nil)
+}
+
+func NewCertsWithOptions(s []string, opts *Options) (Certs, error) {
+	opts = opts.orDefault()
 	if err := validate(s); err != nil {
 		return nil, err
 	}
+	return scanStrings(context.Background(), s, opts)
+}
+
+func scanStrings(ctx context.Context, s []string, opts *Options) (Certs, error) {
+	ctx, span := startSpan(ctx, opts, "cert.scan", "targets", strconv.Itoa(len(s)))
+	var certs Certs
+	var err error
+	if opts.FollowRedirects {
+		certs, err = newCertsFollowingRedirects(ctx, s, opts)
+	} else {
+		certs, err = newCerts(ctx, s, opts)
+	}
+	span.End(err)
+	return certs, err
+}
+
+// newCerts fetches the certificates of s, connecting once to targets that
+// appear more than once, e.g. as example.com and example.com:443, and
+// returning a copy of the result at each of their positions.
+func newCerts(ctx context.Context, s []string, opts *Options) (Certs, error) {
+	var unique []string
+	first := map[string]int{}
+	index := make([]int, len(s))
+	for i, target := range s {
+		key := targetKey(target)
+		j, ok := first[key]
+		if !ok {
+			j = len(unique)
+			first[key] = j
+			unique = append(unique, target)
+		}
+		index[i] = j
+	}
+	if len(unique) < len(s) {
+		opts.logDebug("duplicate targets", "targets", len(s), "unique", len(unique))
+	}
+
+	results, err := scanContext(ctx, len(unique), opts, func(ctx context.Context, i int) *Cert {
+		return newCertContext(ctx, unique[i], opts)
+	})
+	certs := make(Certs, len(s))
+	seen := make([]bool, len(unique))
+	for i, j := range index {
+		if !seen[j] {
+			seen[j] = true
+			certs[i] = results[j]
+			continue
+		}
+		c := *results[j]
+		c.Input = s[i]
+		certs[i] = &c
+	}
+	return certs, err
+}
 
-	type indexer struct {
+// targetKey returns the scheme, lower case host and port of target, which
+// are the same for targets connecting alike.
+func targetKey(target string) string {
+	host, port, err := splitTarget(target)
+	if err != nil {
+		return target
+	}
+	return targetScheme(target) + "://" + net.JoinHostPort(strings.ToLower(host), port)
+}
+
+// scan calls fetch for 0 <= i < n concurrently, at most opts.Concurrency at
+// a time or bounded by tokens if it is 0, and returns the results in order.
+func scan(n int, opts *Options, fetch func(i int) *Cert) Certs {
+	opts.logDebug("scan started", "targets", n)
+	start := time.Now()
+	jobs := make(chan func() *Cert)
+	go func() {
+		for i := 0; i < n; i++ {
+			i := i
+			jobs <- func() *Cert { return fetch(i) }
+		}
+		close(jobs)
+	}()
+	certs := make(Certs, 0, n)
+	scanOrdered(opts.Concurrency, jobs, func(c *Cert) { certs = append(certs, c) })
+	opts.logDebug("scan finished", "targets", n, "elapsed", time.Since(start))
+	return certs
+}
+
+// scanWindow times the concurrency limit is the number of jobs scanOrdered
+// runs or holds the result of at most.
+const scanWindow = 4
+
+// scanOrdered runs the jobs received until jobs is closed, at most
+// concurrency at a time or bounded by tokens if concurrency is 0, and
+// passes their results to emit in the order of jobs. A result waiting for
+// an earlier, slower one holds up further jobs once scanWindow times the
+// limit are pending, so memory use does not grow with the number of jobs.
+func scanOrdered(concurrency int, jobs <-chan func() *Cert, emit func(c *Cert)) {
+	type indexed struct {
 		index int
 		cert  *Cert
 	}
 
-	certs := make(Certs, len(s))
-	ch := make(chan *indexer, len(s))
-	for i, d := range s {
-		go func(i int, d string) {
-			tokens <- struct{}{}
-			ch <- &indexer{i, NewCert(d)}
-			<-tokens
-		}(i, d)
+	limit := tokens
+	if concurrency > 0 {
+		limit = make(chan struct{}, concurrency)
 	}
+	window := make(chan struct{}, scanWindow*cap(limit))
+	results := make(chan indexed, cap(limit))
+	total := make(chan int, 1)
+	go func() {
+		n := 0
+		for job := range jobs {
+			window <- struct{}{}
+			limit <- struct{}{}
+			go func(i int, job func() *Cert) {
+				c := job()
+				<-limit
+				results <- indexed{i, c}
+			}(n, job)
+			n++
+		}
+		total <- n
+	}()
 
-	for range s {
-		i := <-ch
-		certs[i.index] = i.cert
+	pending := map[int]*Cert{}
+	n := -1
+	for next := 0; next != n; {
+		select {
+		case r := <-results:
+			pending[r.index] = r.cert
+		case n = <-total:
+			continue
+		}
+		for c, ok := pending[next]; ok; c, ok = pending[next] {
+			delete(pending, next)
+			emit(c)
+			<-window
+			next++
+		}
 	}
-	return certs, nil
 }
 
 func (certs Certs) String() string {
-	var b bytes.Buffer
-	t := template.Must(template.New("default").Parse(defaultTempl))
-	if err := t.Execute(&b, certs); err != nil {
-		panic(err)
-	}
-	return b.String()
+	return execute("default", defaultTempl, certs)
+}
+
+// String renders c like Certs.String renders each certificate.
+func (c *Cert) String() string {
+	return Certs{c}.String()
 }
 
 func (certs Certs) Markdown() string {
-	var b bytes.Buffer
-	t := template.Must(template.New("markdown").Parse(markdownTempl))
-	if err := t.Execute(&b, certs.escapeStar()); err != nil {
-		panic(err)
-	}
-	return b.String()
+	return execute("markdown", markdownTempl, certs.escapeStar())
 }
 
 func (certs Certs) JSON() []byte {
@@ -161,11 +768,17 @@ func (certs Certs) JSON() []byte {
 	return data
 }
 
+// escapeStar returns copies of certs with "*" in SANs escaped for markdown,
+// leaving certs untouched for other formats.
 func (certs Certs) escapeStar() Certs {
-	for _, cert := range certs {
-		for i, san := range cert.SANs {
-			cert.SANs[i] = strings.Replace(san, "*", "\\*", -1)
+	escaped := make(Certs, len(certs))
+	for i, cert := range certs {
+		c := *cert
+		c.SANs = make([]string, len(cert.SANs))
+		for j, san := range cert.SANs {
+			c.SANs[j] = strings.Replace(san, "*", "\\*", -1)
 		}
+		escaped[i] = &c
 	}
-	return certs
+	return escaped
 }
@@ -1,16 +1,46 @@
 package cert
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
+var realServerCert = serverCert
+
+func startTLSServer(t *testing.T, config *tls.Config) (string, string) {
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	s.TLS = config
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	return host, port
+}
+
 func stubCert() {
-	serverCert = func(host, port string) (*x509.Certificate, string, error) {
-		return &x509.Certificate{
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return &serverInfo{chain: []*x509.Certificate{{
 			Issuer: pkix.Name{
 				CommonName: "CA for test",
 			},
@@ -20,8 +50,74 @@ func stubCert() {
 			DNSNames:  []string{host, "www." + host},
 			NotBefore: time.Date(2017, time.January, 1, 0, 0, 0, 0, time.Local),
 			NotAfter:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.Local),
-		}, "127.0.0.1", nil
+		}}, ip: "127.0.0.1", connectTime: 30 * time.Millisecond, dnsTime: 4 * time.Millisecond, tcpTime: 6 * time.Millisecond, handshakeTime: 20 * time.Millisecond}, nil
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+var testSerial int64
+
+// newTestCert creates a certificate from template signed by ca, or a
+// self-signed one if ca is nil.
+func newTestCert(t *testing.T, template *x509.Certificate, ca *testCA) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestCertWithKey(t, template, ca, key)
+}
+
+func newTestCertWithKey(t *testing.T, template *x509.Certificate, ca *testCA, key *ecdsa.PrivateKey) *testCA {
+	testSerial++
+	template.SerialNumber = big.NewInt(testSerial)
+	if template.NotAfter.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+		template.NotAfter = time.Now().Add(90 * 24 * time.Hour)
+	}
+	parent, parentKey := template, key
+	if ca != nil {
+		parent, parentKey = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert, key}
+}
+
+func newTestCA(t *testing.T, name string, parent *testCA) *testCA {
+	return newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, parent)
+}
+
+func stubChainCert(host string) []*x509.Certificate {
+	return []*x509.Certificate{{Subject: pkix.Name{CommonName: host}}}
+}
+
+func stubChain(chain ...*x509.Certificate) {
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return &serverInfo{chain: chain, ip: "127.0.0.1"}, nil
+	}
+}
+
+func mustServerCert(host, port string) *x509.Certificate {
+	info, err := serverCert(context.Background(), host, port, nil)
+	if err != nil {
+		panic(err)
 	}
+	return info.chain[0]
 }
 
 func TestValidate(t *testing.T) {
@@ -73,7 +169,7 @@ func TestNewCert(t *testing.T) {
 	input := "example.com"
 
 	c := NewCert(input)
-	origCert, _, _ := serverCert(input, defaultPort)
+	origCert := mustServerCert(input, defaultPort)
 
 	if _, ok := interface{}(c).(*Cert); !ok {
 		t.Errorf(`NewCert(%q) was not returned *Cert`, input)
@@ -91,7 +187,7 @@ func TestNewCert(t *testing.T) {
 		t.Errorf(`unexpected Cert.CommonName %q, want %q`, c.CommonName, "example.com")
 	}
 	if len(c.SANs) != 2 {
-		t.Errorf(`unexpected Cert.SANs length %q, want %q`, len(c.SANs), 2)
+		t.Errorf(`unexpected Cert.SANs length %d, want %d`, len(c.SANs), 2)
 	}
 	if c.SANs[0] != "example.com" {
 		t.Errorf(`unexpected Cert.SANs[0] %q, want %q`, c.SANs[0], "example.com")
@@ -108,6 +204,18 @@ func TestNewCert(t *testing.T) {
 	if c.Error != "" {
 		t.Errorf(`unexpected Cert.Error %q, want %q`, c.Error, "")
 	}
+	if c.HandshakeTime != 20*time.Millisecond {
+		t.Errorf(`unexpected Cert.HandshakeTime %v, want %v`, c.HandshakeTime, 20*time.Millisecond)
+	}
+	if c.ConnectTime != 30*time.Millisecond {
+		t.Errorf(`unexpected Cert.ConnectTime %v, want %v`, c.ConnectTime, 30*time.Millisecond)
+	}
+	if c.DNSTime != 4*time.Millisecond {
+		t.Errorf(`unexpected Cert.DNSTime %v, want %v`, c.DNSTime, 4*time.Millisecond)
+	}
+	if c.TCPTime != 6*time.Millisecond {
+		t.Errorf(`unexpected Cert.TCPTime %v, want %v`, c.TCPTime, 6*time.Millisecond)
+	}
 }
 
 func TestNewCerts(t *testing.T) {
@@ -125,7 +233,7 @@ func TestNewCerts(t *testing.T) {
 func TestCertsAsString(t *testing.T) {
 	stubCert()
 
-	origCert, _, _ := serverCert("example.com", defaultPort)
+	origCert := mustServerCert("example.com", defaultPort)
 
 	expected := fmt.Sprintf(`DomainName: example.com
 IP:         127.0.0.1
@@ -135,6 +243,7 @@ NotAfter:   %s
 CommonName: example.com
 SANs:       [example.com www.example.com]
 Error:      
+Status:     EXPIRED
 
 
 `, origCert.NotBefore.String(), origCert.NotAfter.String())
@@ -144,16 +253,22 @@ Error:
 	if certs.String() != expected {
 		t.Errorf(`unexpected return value %q, want %q`, certs.String(), expected)
 	}
+	if certs[0].String() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, certs[0].String(), expected)
+	}
+	if got := fmt.Sprint(certs[0]); got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
 }
 
 func TestCertsAsMarkdown(t *testing.T) {
 	stubCert()
 
-	origCert, _, _ := serverCert("example.com", defaultPort)
+	origCert := mustServerCert("example.com", defaultPort)
 
-	expected := fmt.Sprintf(`DomainName | IP | Issuer | NotBefore | NotAfter | CN | SANs | Error
---- | --- | --- | --- | --- | --- | --- | ---
-example.com | 127.0.0.1 | CA for test | %s | %s | example.com | example.com<br/>www.example.com<br/> | 
+	expected := fmt.Sprintf(`DomainName | IP | Issuer | NotBefore | NotAfter | CN | SANs | Error | Status
+--- | --- | --- | --- | --- | --- | --- | --- | ---
+example.com | 127.0.0.1 | CA for test | %s | %s | example.com | example.com<br/>www.example.com<br/> |  | EXPIRED
 
 `, origCert.NotBefore.String(), origCert.NotAfter.String())
 
@@ -167,9 +282,9 @@ example.com | 127.0.0.1 | CA for test | %s | %s | example.com | example.com<br/>
 func TestCertsAsJSON(t *testing.T) {
 	stubCert()
 
-	origCert, _, _ := serverCert("example.com", defaultPort)
+	origCert := mustServerCert("example.com", defaultPort)
 
-	expected := fmt.Sprintf("[{\"domainName\":\"example.com\",\"ip\":\"127.0.0.1\",\"issuer\":\"CA for test\",\"commonName\":\"example.com\",\"sans\":[\"example.com\",\"www.example.com\"],\"notBefore\":%q,\"notAfter\":%q,\"error\":\"\"}]", origCert.NotBefore.String(), origCert.NotAfter.String())
+	expected := fmt.Sprintf("[{\"domainName\":\"example.com\",\"ip\":\"127.0.0.1\",\"issuer\":\"CA for test\",\"commonName\":\"example.com\",\"sans\":[\"example.com\",\"www.example.com\"],\"sanCount\":2,\"notBefore\":%q,\"notAfter\":%q,\"error\":\"\",\"status\":\"EXPIRED\",\"port\":\"443\",\"input\":\"example.com\",\"connectTime\":30000000,\"dnsTime\":4000000,\"tcpTime\":6000000,\"handshakeTime\":20000000,\"chainNotAfter\":%q}]", origCert.NotBefore.String(), origCert.NotAfter.String(), origCert.NotAfter.String())
 
 	certs, _ := NewCerts([]string{"example.com"})
 
@@ -179,8 +294,8 @@ func TestCertsAsJSON(t *testing.T) {
 }
 
 func TestCertsEscapeStarInSANs(t *testing.T) {
-	serverCert = func(host, port string) (*x509.Certificate, string, error) {
-		return &x509.Certificate{
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return &serverInfo{chain: []*x509.Certificate{{
 			Issuer: pkix.Name{
 				CommonName: "CA for test",
 			},
@@ -190,7 +305,7 @@ func TestCertsEscapeStarInSANs(t *testing.T) {
 			DNSNames:  []string{host, "*." + host}, // include star
 			NotBefore: time.Date(2017, time.January, 1, 0, 0, 0, 0, time.Local),
 			NotAfter:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.Local),
-		}, "127.0.0.1", nil
+		}}, ip: "127.0.0.1"}, nil
 	}
 
 	certs, _ := NewCerts([]string{"example.com"})
@@ -201,3 +316,378 @@ func TestCertsEscapeStarInSANs(t *testing.T) {
 		t.Errorf(`unexpected escaped value %q, want %q`, certs[0].SANs[1], "\\*.example.com")
 	}
 }
+
+func TestChainSize(t *testing.T) {
+	chain := []*x509.Certificate{
+		{Raw: make([]byte, 1200)},
+		{Raw: make([]byte, 1100)},
+	}
+	if size := chainSize(chain); size != 2300 {
+		t.Errorf(`unexpected chain size %d, want %d`, size, 2300)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	var tests = []struct {
+		notAfter time.Time
+		want     string
+	}{
+		{time.Date(2017, time.December, 31, 0, 0, 0, 0, time.UTC), StatusExpired},
+		{time.Date(2018, time.January, 10, 0, 0, 0, 0, time.UTC), StatusExpiring},
+		{time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC), StatusOK},
+	}
+	for _, test := range tests {
+		if got := status(test.notAfter, ExpiringThreshold); got != test.want {
+			t.Errorf("status(%v) = %q, want %q", test.notAfter, got, test.want)
+		}
+	}
+}
+
+func TestNewCertError(t *testing.T) {
+	c := NewCert("example.com:443:443")
+
+	if c.Error == "" {
+		t.Error(`unexpected empty Cert.Error, want error`)
+	}
+	if c.Status != StatusError {
+		t.Errorf(`unexpected Cert.Status %q, want %q`, c.Status, StatusError)
+	}
+}
+
+func TestNewCertWithOptions(t *testing.T) {
+	var got *Options
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		got = opts
+		return &serverInfo{chain: []*x509.Certificate{{}}}, nil
+	}
+	defer stubCert()
+
+	NewCertWithOptions("example.com", &Options{InsecureSkipVerify: true})
+	if got == nil || !got.InsecureSkipVerify {
+		t.Errorf(`unexpected options %+v, want InsecureSkipVerify`, got)
+	}
+
+	SkipVerify = true
+	defer func() { SkipVerify = false }()
+	NewCert("example.com")
+	if got == nil || !got.InsecureSkipVerify {
+		t.Errorf(`unexpected options %+v, want InsecureSkipVerify from SkipVerify`, got)
+	}
+}
+
+func TestNewCertOCSPServers(t *testing.T) {
+	stubChain(&x509.Certificate{OCSPServer: []string{"http://ocsp.example.com"}})
+	defer stubCert()
+
+	c := NewCert("example.com")
+	if len(c.OCSPServers) != 1 || c.OCSPServers[0] != "http://ocsp.example.com" {
+		t.Errorf(`unexpected Cert.OCSPServers %v, want %v`, c.OCSPServers, []string{"http://ocsp.example.com"})
+	}
+}
+
+func TestNewCertIssuerURLs(t *testing.T) {
+	stubChain(&x509.Certificate{IssuingCertificateURL: []string{"http://ca.example.com/intermediate.crt"}})
+	defer stubCert()
+
+	c := NewCert("example.com")
+	if len(c.IssuerURLs) != 1 || c.IssuerURLs[0] != "http://ca.example.com/intermediate.crt" {
+		t.Errorf(`unexpected Cert.IssuerURLs %v, want %v`, c.IssuerURLs, []string{"http://ca.example.com/intermediate.crt"})
+	}
+}
+
+func TestNewCertX509Version(t *testing.T) {
+	stubChain(&x509.Certificate{Version: 1})
+	defer stubCert()
+
+	if c := NewCert("example.com"); c.X509Version != 1 {
+		t.Errorf(`unexpected Cert.X509Version %d, want %d`, c.X509Version, 1)
+	}
+}
+
+func TestNewCertBasicConstraints(t *testing.T) {
+	var tests = []struct {
+		cert       *x509.Certificate
+		isCA       bool
+		maxPathLen int
+	}{
+		{&x509.Certificate{}, false, -1},
+		{&x509.Certificate{IsCA: true}, false, -1},
+		{&x509.Certificate{BasicConstraintsValid: true, IsCA: true, MaxPathLen: -1}, true, -1},
+		{&x509.Certificate{BasicConstraintsValid: true, IsCA: true, MaxPathLenZero: true}, true, 0},
+		{&x509.Certificate{BasicConstraintsValid: true, IsCA: true, MaxPathLen: 2}, true, 2},
+	}
+	defer stubCert()
+
+	for i, test := range tests {
+		stubChain(test.cert)
+		c := NewCert("example.com")
+		if c.IsCA != test.isCA {
+			t.Errorf(`#%d unexpected Cert.IsCA %v, want %v`, i, c.IsCA, test.isCA)
+		}
+		got := -1
+		if c.MaxPathLen != nil {
+			got = *c.MaxPathLen
+		}
+		if got != test.maxPathLen {
+			t.Errorf(`#%d unexpected Cert.MaxPathLen %d, want %d`, i, got, test.maxPathLen)
+		}
+	}
+}
+
+func TestNewCertLeafSignatureValid(t *testing.T) {
+	root := newTestCA(t, "Root CA", nil)
+	intermediate := newTestCA(t, "Intermediate CA", root)
+	reissued := newTestCA(t, "Intermediate CA", root)
+	leaf := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}}, intermediate)
+	defer stubCert()
+
+	stubChain(leaf.cert)
+	if c := NewCert("example.com"); c.LeafSignatureValid != nil {
+		t.Errorf(`unexpected Cert.LeafSignatureValid %v, want nil`, *c.LeafSignatureValid)
+	}
+
+	stubChain(leaf.cert, intermediate.cert)
+	if c := NewCert("example.com"); c.LeafSignatureValid == nil || !*c.LeafSignatureValid {
+		t.Error(`unexpected Cert.LeafSignatureValid, want true`)
+	}
+
+	stubChain(leaf.cert, reissued.cert)
+	if c := NewCert("example.com"); c.LeafSignatureValid == nil || *c.LeafSignatureValid {
+		t.Error(`unexpected Cert.LeafSignatureValid, want false`)
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	var tests = []struct {
+		input string
+		host  string
+		port  string
+		err   bool
+	}{
+		{"example.com", "example.com", defaultPort, false},
+		{"example.com:8443", "example.com", "8443", false},
+		{"https://example.com/path", "example.com", defaultPort, false},
+		{"https://example.com:8443", "example.com", "8443", false},
+		{"gopher://example.com", "example.com", "", true},
+		{"smtp://mail.example.com", "mail.example.com", "25", false},
+		{"LDAPS://ldap.example.com", "ldap.example.com", "636", false},
+		{"imap://mail.example.com:10143", "mail.example.com", "10143", false},
+		{"example.com:https", "example.com", "443", false},
+		{"mail.example.com:imaps", "mail.example.com", "993", false},
+		{"example.com:no-such-service", "example.com", "", true},
+	}
+	for _, test := range tests {
+		host, port, err := splitTarget(test.input)
+		if host != test.host || port != test.port || (err != nil) != test.err {
+			t.Errorf("splitTarget(%q) = %q, %q, %v", test.input, host, port, err)
+		}
+	}
+}
+
+func TestNewCertPortAndInput(t *testing.T) {
+	stubCert()
+
+	certs, _ := NewCerts([]string{"example.com", "https://example.com:8443/"})
+	for i, want := range []struct{ port, input, hostport string }{
+		{"443", "example.com", "example.com"},
+		{"8443", "https://example.com:8443/", "https://example.com:8443"},
+	} {
+		if certs[i].Port != want.port || certs[i].Input != want.input {
+			t.Errorf(`unexpected port %q and input %q, want %q and %q`, certs[i].Port, certs[i].Input, want.port, want.input)
+		}
+		if got := hostport(certs[i]); got != want.hostport {
+			t.Errorf(`unexpected hostport %q, want %q`, got, want.hostport)
+		}
+	}
+
+	certs, err := NewCertsFromTargets([]Target{{Host: "example.com", Port: "8443"}}, nil)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if c := certs[0]; c.Port != "8443" || c.Input != "example.com" {
+		t.Errorf(`unexpected port %q and input %q, want "8443" and "example.com"`, c.Port, c.Input)
+	}
+}
+
+func TestTwoPortsOfOneHost(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		notAfter := time.Date(2018, time.January, 10, 0, 0, 0, 0, time.UTC)
+		if port == "8443" {
+			notAfter = notAfter.AddDate(0, 0, 10)
+		}
+		return &serverInfo{chain: []*x509.Certificate{{
+			Raw:      []byte("leaf " + port),
+			Subject:  pkix.Name{CommonName: host},
+			DNSNames: []string{host},
+			NotAfter: notAfter,
+		}}}, nil
+	}
+	defer stubCert()
+
+	certs, _ := NewCerts([]string{"example.com", "example.com:8443"})
+
+	s := &WatchState{Ladder: NewLadder(30 * 24 * time.Hour)}
+	var got []string
+	ns, _ := s.Update(certs)
+	for _, n := range ns {
+		got = append(got, n.DomainName)
+	}
+	if want := []string{"example.com", "example.com:8443"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected notifications %q, want %q`, got, want)
+	}
+	if _, changes := s.Update(certs); changes != nil {
+		t.Errorf(`unexpected changes %v, want none`, changes)
+	}
+	_, changes := s.Update(certs[:1])
+	if want := []Change{{DomainName: "example.com:8443", Old: certs[1].Status}}; !reflect.DeepEqual(changes, want) {
+		t.Errorf(`unexpected changes %v, want %v`, changes, want)
+	}
+
+	dir := t.TempDir()
+	if err := certs.WritePEM(dir); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	for file, want := range map[string]string{"example.com.pem": "leaf 443", "example.com_8443.pem": "leaf 8443"} {
+		data, _ := os.ReadFile(filepath.Join(dir, file))
+		if block, _ := pem.Decode(data); block == nil || string(block.Bytes) != want {
+			t.Errorf(`unexpected content of %s, want %q`, file, want)
+		}
+	}
+
+	ical := certs.ICal(0)
+	for _, want := range []string{"UID:example.com-20180110@cert", "UID:example.com:8443-20180120@cert"} {
+		if !strings.Contains(ical, want) {
+			t.Errorf(`unexpected calendar %q, want %q`, ical, want)
+		}
+	}
+
+	metrics := (&StatsdSink{}).metrics(certs)
+	if want := []string{"cert.scans:1|c", "cert.days_remaining:9.00|g|#domain:example.com", "cert.days_remaining:19.00|g|#domain:example.com:8443"}; !reflect.DeepEqual(metrics, want) {
+		t.Errorf(`unexpected metrics %q, want %q`, metrics, want)
+	}
+
+	var report struct {
+		Runs []struct {
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(certs.SARIF(), &report); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	uris := map[string]bool{}
+	for _, r := range report.Runs[0].Results {
+		uris[r.Locations[0].PhysicalLocation.ArtifactLocation.URI] = true
+	}
+	if want := map[string]bool{"example.com": true, "example.com:8443": true}; !reflect.DeepEqual(uris, want) {
+		t.Errorf(`unexpected locations %v, want %v`, uris, want)
+	}
+}
+
+func TestNewCertScheme(t *testing.T) {
+	var mu sync.Mutex
+	startTLS := map[string]string{}
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		startTLS[host] = opts.StartTLS
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	certs, _ := NewCerts([]string{"smtp://mail.example.com:2525", "smtps://smtp.example.com:587", "ldap.example.com"})
+	for i, want := range []struct{ scheme, startTLS, hostport string }{
+		{"smtp", "smtp", "smtp://mail.example.com:2525"},
+		{"smtps", StartTLSNone, "smtps://smtp.example.com:587"},
+		{"", "", "ldap.example.com"},
+	} {
+		c := certs[i]
+		if c.Scheme != want.scheme || startTLS[c.DomainName] != want.startTLS {
+			t.Errorf(`unexpected scheme %q and STARTTLS %q, want %q and %q`, c.Scheme, startTLS[c.DomainName], want.scheme, want.startTLS)
+		}
+		if got := hostport(c); got != want.hostport {
+			t.Errorf(`unexpected hostport %q, want %q`, got, want.hostport)
+		}
+	}
+
+	certs, _ = NewCertsFromTargets([]Target{{Host: "imap://mail.example.com", Port: "10143"}}, nil)
+	if c := certs[0]; c.Scheme != "imap" || c.Port != "10143" || startTLS[c.DomainName] != "imap" {
+		t.Errorf(`unexpected scheme %q, port %q and STARTTLS %q, want "imap", "10143" and "imap"`, c.Scheme, c.Port, startTLS[c.DomainName])
+	}
+}
+
+func TestNewCertsDeduplicates(t *testing.T) {
+	var mu sync.Mutex
+	dials := map[string]int{}
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dials[net.JoinHostPort(host, port)]++
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	input := []string{"example.com", "example.org", "Example.com:443", "example.com:8443", "example.com"}
+	certs, err := NewCerts(input)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	want := map[string]int{"example.com:443": 1, "example.org:443": 1, "example.com:8443": 1}
+	if !reflect.DeepEqual(dials, want) {
+		t.Errorf(`unexpected dials %v, want %v`, dials, want)
+	}
+	for i, c := range certs {
+		if c.Input != input[i] {
+			t.Errorf(`unexpected input %q at %d, want %q`, c.Input, i, input[i])
+		}
+	}
+	if certs[0] == certs[4] {
+		t.Error(`unexpected shared result, want a copy`)
+	}
+}
+
+func TestChainNotAfter(t *testing.T) {
+	ca := newTestCA(t, "Root CA", nil)
+	inter := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Short Intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(60 * 24 * time.Hour),
+	}, ca)
+	leaf := newTestCert(t, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		DNSNames:  []string{"example.com"},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(90 * 24 * time.Hour),
+	}, inter)
+	stubChain(leaf.cert, inter.cert)
+	defer stubCert()
+
+	c := NewCert("example.com")
+	if want := inter.cert.NotAfter.In(time.Local).String(); c.ChainNotAfter != want || c.Status != StatusOK {
+		t.Errorf(`unexpected chain not after %q and status %s, want %q and %s`, c.ChainNotAfter, c.Status, want, StatusOK)
+	}
+	want := "Intermediate Short Intermediate expires at " + c.ChainNotAfter + ", before the certificate."
+	if len(c.Warnings) != 1 || c.Warnings[0] != want {
+		t.Errorf(`unexpected warnings %q, want %q`, c.Warnings, want)
+	}
+
+	stubChain(leaf.cert)
+	if c := NewCert("example.com"); c.ChainNotAfter != c.NotAfter {
+		t.Errorf(`unexpected chain not after %q, want %q`, c.ChainNotAfter, c.NotAfter)
+	}
+}
@@ -0,0 +1,67 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+)
+
+// chainIssues reports configuration problems in the order and content of
+// the presented chain which clients usually tolerate silently.
+func chainIssues(chain []*x509.Certificate) []string {
+	var issues []string
+
+	dup := make([]bool, len(chain))
+	for i := 1; i < len(chain); i++ {
+		for j := 0; j < i; j++ {
+			if len(chain[i].Raw) > 0 && bytes.Equal(chain[i].Raw, chain[j].Raw) {
+				dup[i] = true
+				issues = append(issues, fmt.Sprintf("duplicate certificate: %s", chain[i].Subject.CommonName))
+				break
+			}
+		}
+	}
+
+	// Walk from the leaf to the top, picking the issuer among the
+	// presented certificates wherever it is.
+	used := make([]bool, len(chain))
+	used[0] = true
+	var path []int
+	for cur := chain[0]; ; {
+		next := -1
+		for j := 1; j < len(chain); j++ {
+			if !used[j] && !dup[j] && issuedBy(cur, chain[j]) {
+				next = j
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		used[next] = true
+		path = append(path, next)
+		cur = chain[next]
+	}
+
+	for i, j := range path {
+		if j != i+1 {
+			issues = append(issues, "chain out of order")
+			break
+		}
+	}
+	for _, j := range path {
+		if issuedBy(chain[j], chain[j]) {
+			issues = append(issues, fmt.Sprintf("root certificate included: %s", chain[j].Subject.CommonName))
+		}
+	}
+	for j := 1; j < len(chain); j++ {
+		if !used[j] && !dup[j] {
+			issues = append(issues, fmt.Sprintf("unrelated certificate: %s", chain[j].Subject.CommonName))
+		}
+	}
+	return issues
+}
+
+func issuedBy(child, parent *x509.Certificate) bool {
+	return bytes.Equal(child.RawIssuer, parent.RawSubject) && child.CheckSignatureFrom(parent) == nil
+}
@@ -0,0 +1,45 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+)
+
+func TestChainIssues(t *testing.T) {
+	root := newTestCA(t, "Root CA", nil)
+	intermediate := newTestCA(t, "Intermediate CA", root)
+	issuing := newTestCA(t, "Issuing CA", intermediate)
+	other := newTestCA(t, "Other CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}}, issuing).cert
+
+	var tests = []struct {
+		chain []*x509.Certificate
+		want  []string
+	}{
+		{[]*x509.Certificate{leaf}, nil},
+		{[]*x509.Certificate{leaf, issuing.cert, intermediate.cert}, nil},
+		{[]*x509.Certificate{leaf, intermediate.cert, issuing.cert}, []string{"chain out of order"}},
+		{[]*x509.Certificate{leaf, issuing.cert, intermediate.cert, root.cert}, []string{"root certificate included: Root CA"}},
+		{[]*x509.Certificate{leaf, issuing.cert, other.cert}, []string{"unrelated certificate: Other CA"}},
+		{[]*x509.Certificate{leaf, issuing.cert, issuing.cert}, []string{"duplicate certificate: Issuing CA"}},
+	}
+	for i, test := range tests {
+		if got := chainIssues(test.chain); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("#%d chainIssues() = %q, want %q", i, got, test.want)
+		}
+	}
+}
+
+func TestNewCertChainIssues(t *testing.T) {
+	root := newTestCA(t, "Root CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}}, root).cert
+	stubChain(leaf, root.cert)
+	defer stubCert()
+
+	c := NewCert("example.com")
+	if !reflect.DeepEqual(c.ChainIssues, []string{"root certificate included: Root CA"}) {
+		t.Errorf(`unexpected Cert.ChainIssues %q`, c.ChainIssues)
+	}
+}
@@ -0,0 +1,80 @@
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Finding is a problem found on a certificate, by a built-in rule or a
+// Checker.
+type Finding struct {
+	Rule string `json:"rule"`
+	// Severity is "error" or "warning", or "ignore" if a Policy says so.
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Checker inspects a fetched certificate. c is the result so far and leaf
+// the certificate it was built from.
+type Checker interface {
+	Check(c *Cert, leaf *x509.Certificate) []Finding
+}
+
+// CheckerFunc adapts a function to Checker.
+type CheckerFunc func(c *Cert, leaf *x509.Certificate) []Finding
+
+// Check calls f.
+func (f CheckerFunc) Check(c *Cert, leaf *x509.Certificate) []Finding {
+	return f(c, leaf)
+}
+
+// runCheckers appends the findings of checkers to c.Findings. Certificates
+// that could not be fetched are not checked.
+func runCheckers(c *Cert, checkers []Checker) {
+	if len(c.chain) == 0 {
+		return
+	}
+	for _, checker := range checkers {
+		c.Findings = append(c.Findings, checker.Check(c, c.chain[0])...)
+	}
+}
+
+// ApprovedIssuers reports certificates whose issuer common name is not one
+// of issuers as rule "unapproved-issuer".
+func ApprovedIssuers(issuers ...string) Checker {
+	return CheckerFunc(func(c *Cert, leaf *x509.Certificate) []Finding {
+		for _, issuer := range issuers {
+			if leaf.Issuer.CommonName == issuer {
+				return nil
+			}
+		}
+		return []Finding{{
+			Rule:     "unapproved-issuer",
+			Severity: "error",
+			Message:  fmt.Sprintf("Issuer %q is not approved.", leaf.Issuer.CommonName),
+		}}
+	})
+}
+
+// NamingConvention reports SANs that do not match re as rule
+// "naming-convention".
+func NamingConvention(re *regexp.Regexp) Checker {
+	return CheckerFunc(func(c *Cert, leaf *x509.Certificate) []Finding {
+		var bad []string
+		for _, name := range leaf.DNSNames {
+			if !re.MatchString(name) {
+				bad = append(bad, name)
+			}
+		}
+		if len(bad) == 0 {
+			return nil
+		}
+		return []Finding{{
+			Rule:     "naming-convention",
+			Severity: "warning",
+			Message:  fmt.Sprintf("Names %s do not match %s.", strings.Join(bad, ", "), re),
+		}}
+	})
+}
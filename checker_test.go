@@ -0,0 +1,61 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheckers(t *testing.T) {
+	ca := newTestCA(t, "Approved CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "www.example.com"}, DNSNames: []string{"www.example.com", "legacy-box"}}, ca)
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		if host == "down.example.com" {
+			return nil, errors.New("connection refused")
+		}
+		return &serverInfo{chain: []*x509.Certificate{leaf.cert, ca.cert}}, nil
+	}
+	defer stubCert()
+
+	var seen []string
+	opts := &Options{Checkers: []Checker{
+		ApprovedIssuers("Other CA"),
+		NamingConvention(regexp.MustCompile(`\.example\.com$`)),
+		CheckerFunc(func(c *Cert, leaf *x509.Certificate) []Finding {
+			seen = append(seen, c.DomainName+" "+leaf.Subject.CommonName)
+			return nil
+		}),
+	}}
+	certs, _ := NewCertsWithOptions([]string{"example.com", "down.example.com"}, opts)
+
+	if len(seen) != 1 || seen[0] != "example.com www.example.com" {
+		t.Errorf(`unexpected checked certificates %q, want only example.com`, seen)
+	}
+	want := []Finding{
+		{"unapproved-issuer", "error", `Issuer "Approved CA" is not approved.`},
+		{"naming-convention", "warning", `Names legacy-box do not match \.example\.com$.`},
+	}
+	if got := certs[0].Findings; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf(`unexpected findings %+v, want %+v`, got, want)
+	}
+	if len(certs[1].Findings) != 0 {
+		t.Errorf(`unexpected findings %+v of failed target, want none`, certs[1].Findings)
+	}
+
+	if got := string(certs.SARIF()); !strings.Contains(got, `{"id":"unapproved-issuer","shortDescription":{"text":"unapproved-issuer"},"defaultConfiguration":{"level":"error"}}`) ||
+		!strings.Contains(got, `"ruleId":"naming-convention","level":"warning"`) {
+		t.Errorf(`unexpected SARIF %s, want custom rules`, got)
+	}
+	if got := certs.CEF(); !strings.Contains(got, "|naming-convention|naming-convention|5|dhost=example.com") {
+		t.Errorf(`unexpected CEF %q, want custom finding`, got)
+	}
+
+	opts.Checkers = []Checker{ApprovedIssuers("Other CA", "Approved CA")}
+	if c := NewCertWithOptions("example.com", opts); len(c.Findings) != 0 {
+		t.Errorf(`unexpected findings %+v, want none`, c.Findings)
+	}
+}
@@ -0,0 +1,73 @@
+package cert
+
+import (
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+const (
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+var colorTempl = strings.NewReplacer(
+	"{{.NotAfter}}", "{{colorExpiry .}}",
+	"{{trunc . .Error}}", "{{colorError (trunc . .Error)}}",
+).Replace(defaultTempl)
+
+// Color renders certs like String, highlighting expired certificates in red,
+// certificates expiring within ExpiringThreshold in yellow and errors in red.
+func (certs Certs) Color() string {
+	var b strings.Builder
+	t := template.Must(template.New("color").Funcs(funcs).Funcs(template.FuncMap{
+		"colorExpiry": colorExpiry,
+		"colorError":  colorError,
+	}).Parse(colorTempl))
+	if err := t.Execute(&b, certs); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
+// WriteColor writes the colored output to w if w is a terminal and the
+// NO_COLOR environment variable is unset, and the plain output otherwise.
+func (certs Certs) WriteColor(w io.Writer) error {
+	out := certs.String()
+	if isTerminal(w) && os.Getenv("NO_COLOR") == "" {
+		out = certs.Color()
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+func colorExpiry(c *Cert) string {
+	switch c.Status {
+	case StatusExpired:
+		return ansiRed + c.NotAfter + ansiReset
+	case StatusExpiring:
+		return ansiYellow + c.NotAfter + ansiReset
+	}
+	return c.NotAfter
+}
+
+func colorError(s string) string {
+	if s == "" {
+		return s
+	}
+	return ansiRed + s + ansiReset
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,45 @@
+package cert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColorExpiry(t *testing.T) {
+	var tests = []struct {
+		status string
+		want   string
+	}{
+		{StatusExpired, ansiRed + "x" + ansiReset},
+		{StatusExpiring, ansiYellow + "x" + ansiReset},
+		{StatusOK, "x"},
+		{StatusError, "x"},
+	}
+	for _, test := range tests {
+		got := colorExpiry(&Cert{NotAfter: "x", Status: test.status})
+		if got != test.want {
+			t.Errorf("colorExpiry(%s) = %q, want %q", test.status, got, test.want)
+		}
+	}
+}
+
+func TestCertsAsColor(t *testing.T) {
+	certs := Certs{{DomainName: "example.com", Error: "connection refused"}}
+
+	if !strings.Contains(certs.Color(), "Error:      "+ansiRed+"connection refused"+ansiReset) {
+		t.Errorf(`unexpected return value %q, want highlighted error`, certs.Color())
+	}
+}
+
+func TestCertsWriteColorToNonTerminal(t *testing.T) {
+	certs := Certs{{DomainName: "example.com", Error: "connection refused"}}
+
+	var b bytes.Buffer
+	if err := certs.WriteColor(&b); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if b.String() != certs.String() {
+		t.Errorf(`unexpected output %q, want %q`, b.String(), certs.String())
+	}
+}
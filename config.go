@@ -0,0 +1,250 @@
+package cert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// Config describes a scan setup that is too large for flags: targets,
+// TLS settings, thresholds, outputs and sinks. It is read from JSON, YAML or
+// TOML with the keys of the JSON tags.
+type Config struct {
+	Targets            []Target `json:"targets"`
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty"`
+	MinTLS             string   `json:"minTLS,omitempty"`
+	MaxTLS             string   `json:"maxTLS,omitempty"`
+	CipherSuites       string   `json:"cipherSuites,omitempty"`
+	Concurrency        int      `json:"concurrency,omitempty"`
+	Timeout            string   `json:"timeout,omitempty"`
+	Proxies            ProxyMap `json:"proxies,omitempty"`
+
+	// ApprovedIssuers and NamingConvention, a regular expression every SAN
+	// must match, add the checkers of the same name.
+	ApprovedIssuers  []string `json:"approvedIssuers,omitempty"`
+	NamingConvention string   `json:"namingConvention,omitempty"`
+	// Policy overrides the severity of findings.
+	Policy *Policy `json:"policy,omitempty"`
+	// DistrustedRoots adds the DistrustedRoots checker with these roots
+	// added to DefaultDistrustedRoots.
+	DistrustedRoots []DistrustedRoot `json:"distrustedRoots,omitempty"`
+
+	// WarnDays is the number of days before expiry a certificate is
+	// expiring, NotifyDays the lead times of watch notifications.
+	WarnDays   int   `json:"warnDays,omitempty"`
+	NotifyDays []int `json:"notifyDays,omitempty"`
+
+	Outputs []ConfigOutput `json:"outputs,omitempty"`
+
+	Syslog *SyslogSink `json:"syslog,omitempty"`
+	HTTP   *HTTPSink   `json:"http,omitempty"`
+	Statsd *StatsdSink `json:"statsd,omitempty"`
+	NATS   *NATSConfig `json:"nats,omitempty"`
+}
+
+// Duration is a time.Duration read from JSON as a string such as "2s", or
+// as a number of nanoseconds, and written as a string.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("Invalid duration %s.", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("Invalid duration %q.", s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ConfigOutput writes results in Format to File.
+type ConfigOutput struct {
+	Format string `json:"format"`
+	File   string `json:"file"`
+}
+
+// NATSConfig names the NATS server and subject to publish to.
+type NATSConfig struct {
+	URL     string `json:"url"`
+	Subject string `json:"subject"`
+}
+
+// LoadConfig reads a Config from the file at path, which is YAML if its
+// name ends in .yaml or .yml, TOML if it ends in .toml and JSON otherwise.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := unmarshalConfig(path, data, &c); err != nil {
+		return nil, fmt.Errorf("Invalid config %s: %v.", path, err)
+	}
+	for i, t := range c.Targets {
+		if t.Host == "" {
+			return nil, fmt.Errorf("Target %d has no host.", i+1)
+		}
+	}
+	for _, o := range c.Outputs {
+		if _, ok := formats[o.Format]; !ok {
+			return nil, fmt.Errorf("Unknown format %q.", o.Format)
+		}
+	}
+	if c.WarnDays < 0 {
+		return nil, fmt.Errorf("Invalid warnDays %d.", c.WarnDays)
+	}
+	for _, days := range c.NotifyDays {
+		if days < 0 {
+			return nil, fmt.Errorf("Invalid notifyDays %d.", days)
+		}
+	}
+	return &c, nil
+}
+
+// unmarshalConfig decodes data into c by the extension of path. YAML and
+// TOML are converted to JSON first, so values have the types they have in
+// JSON, e.g. ports are strings.
+func unmarshalConfig(path string, data []byte, c *Config) error {
+	var v any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return err
+		}
+	case ".toml":
+		var m map[string]any
+		if _, err := toml.Decode(string(data), &m); err != nil {
+			return err
+		}
+		v = m
+	default:
+		return json.Unmarshal(data, c)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, c)
+}
+
+// Options returns DefaultOptions with the TLS settings, checkers and
+// WarnDays of c applied.
+func (c *Config) Options() (*Options, error) {
+	opts := DefaultOptions()
+	opts.InsecureSkipVerify = opts.InsecureSkipVerify || c.InsecureSkipVerify
+	opts.Concurrency = c.Concurrency
+	opts.ExpiringThreshold = time.Duration(c.WarnDays) * 24 * time.Hour
+	var err error
+	if c.Timeout != "" {
+		if opts.Timeout, err = time.ParseDuration(c.Timeout); err != nil {
+			return nil, fmt.Errorf("Invalid timeout %q.", c.Timeout)
+		}
+	}
+	if c.Proxies != nil {
+		opts.Proxy = c.Proxies.Proxy
+	}
+	if len(c.ApprovedIssuers) > 0 {
+		opts.Checkers = append(opts.Checkers, ApprovedIssuers(c.ApprovedIssuers...))
+	}
+	if c.NamingConvention != "" {
+		re, err := regexp.Compile(c.NamingConvention)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid naming convention %q.", c.NamingConvention)
+		}
+		opts.Checkers = append(opts.Checkers, NamingConvention(re))
+	}
+	if len(c.DistrustedRoots) > 0 {
+		if err := validateDistrustedRoots(c.DistrustedRoots, "config"); err != nil {
+			return nil, err
+		}
+		roots := append(append([]DistrustedRoot{}, DefaultDistrustedRoots...), c.DistrustedRoots...)
+		opts.Checkers = append(opts.Checkers, DistrustedRoots(roots...))
+	}
+	if c.Policy != nil {
+		if err := c.Policy.validate(); err != nil {
+			return nil, err
+		}
+		opts.Policy = c.Policy
+	}
+	if c.MinTLS != "" {
+		if opts.MinVersion, err = ParseTLSVersion(c.MinTLS); err != nil {
+			return nil, err
+		}
+	}
+	if c.MaxTLS != "" {
+		if opts.MaxVersion, err = ParseTLSVersion(c.MaxTLS); err != nil {
+			return nil, err
+		}
+	}
+	if c.CipherSuites != "" {
+		if opts.CipherSuites, err = ParseCipherSuites(c.CipherSuites); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// Thresholds returns NotifyDays as durations, or DefaultLadder if unset.
+func (c *Config) Thresholds() []time.Duration {
+	if len(c.NotifyDays) == 0 {
+		return DefaultLadder
+	}
+	thresholds := make([]time.Duration, len(c.NotifyDays))
+	for i, days := range c.NotifyDays {
+		thresholds[i] = time.Duration(days) * 24 * time.Hour
+	}
+	return thresholds
+}
+
+// Sinks returns the sinks configured in c, connecting to NATS if set.
+func (c *Config) Sinks() ([]Sink, error) {
+	var sinks []Sink
+	if c.Syslog != nil {
+		sinks = append(sinks, c.Syslog)
+	}
+	if c.HTTP != nil {
+		sinks = append(sinks, c.HTTP)
+	}
+	if c.Statsd != nil {
+		sinks = append(sinks, c.Statsd)
+	}
+	if c.NATS != nil {
+		subject := c.NATS.Subject
+		if subject == "" {
+			subject = "cert.results"
+		}
+		p, err := DialNATS(c.NATS.URL, subject)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, p)
+	}
+	return sinks, nil
+}
+
+// Scan fetches the certificates of c.Targets.
+func (c *Config) Scan() (Certs, error) {
+	opts, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewCertsFromTargets(c.Targets, opts)
+}
@@ -0,0 +1,204 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, s string) string {
+	return writeConfigFile(t, "cert.json", s)
+}
+
+func writeConfigFile(t *testing.T, name, s string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(s), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"targets": [{"host": "example.com", "labels": {"team": "web"}}, {"host": "mail.example.com", "port": "993"}],
+		"insecureSkipVerify": true,
+		"minTLS": "1.2",
+		"concurrency": 4,
+		"timeout": "3s",
+		"approvedIssuers": ["Example CA"],
+		"namingConvention": "\\.example\\.com$",
+		"distrustedRoots": [{"name": "Old Root", "date": "2025-06-30", "spki": "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}],
+		"warnDays": 21,
+		"notifyDays": [14, 3],
+		"outputs": [{"format": "json", "file": "certs.json"}],
+		"syslog": {"network": "udp", "addr": "localhost:514", "facility": 16},
+		"statsd": {"addr": "localhost:8125", "tags": ["env:prod"]},
+		"http": {"url": "https://hec.example.com/services/collector", "token": "Splunk t", "hec": true, "backoff": "2s"}
+	}`)
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	if len(c.Targets) != 2 || c.Targets[0].Labels["team"] != "web" || c.Targets[1].Port != "993" {
+		t.Errorf(`unexpected targets %+v`, c.Targets)
+	}
+	opts, err := c.Options()
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if !opts.InsecureSkipVerify || opts.MinVersion != tls.VersionTLS12 || opts.Concurrency != 4 || opts.Timeout != 3*time.Second || len(opts.Checkers) != 3 || opts.ExpiringThreshold != 21*24*time.Hour {
+		t.Errorf(`unexpected options %+v`, opts)
+	}
+	if want := []time.Duration{14 * 24 * time.Hour, 3 * 24 * time.Hour}; !reflect.DeepEqual(c.Thresholds(), want) {
+		t.Errorf(`unexpected thresholds %v, want %v`, c.Thresholds(), want)
+	}
+	if want := []ConfigOutput{{Format: "json", File: "certs.json"}}; !reflect.DeepEqual(c.Outputs, want) {
+		t.Errorf(`unexpected outputs %+v, want %+v`, c.Outputs, want)
+	}
+
+	sinks, err := c.Sinks()
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	want := []Sink{
+		&SyslogSink{Network: "udp", Addr: "localhost:514", Facility: 16},
+		&HTTPSink{URL: "https://hec.example.com/services/collector", Token: "Splunk t", HEC: true, Backoff: Duration(2 * time.Second)},
+		&StatsdSink{Addr: "localhost:8125", Tags: []string{"env:prod"}},
+	}
+	if !reflect.DeepEqual(sinks, want) {
+		t.Errorf(`unexpected sinks %+v, want %+v`, sinks, want)
+	}
+}
+
+func TestLoadConfigYAMLTOML(t *testing.T) {
+	yaml := `
+targets:
+  - host: example.com
+    labels: {team: web}
+  - host: mail.example.com
+    port: "993"
+concurrency: 4
+proxies:
+  "*.internal": direct
+warnDays: 21
+notifyDays: [14, 3]
+http:
+  url: https://hec.example.com/services/collector
+  backoff: 2s
+`
+	toml := `
+concurrency = 4
+warnDays = 21
+notifyDays = [14, 3]
+
+[[targets]]
+host = "example.com"
+labels = {team = "web"}
+
+[[targets]]
+host = "mail.example.com"
+port = "993"
+
+[proxies]
+"*.internal" = "direct"
+
+[http]
+url = "https://hec.example.com/services/collector"
+backoff = "2s"
+`
+	want := &Config{
+		Targets:     []Target{{Host: "example.com", Labels: map[string]string{"team": "web"}}, {Host: "mail.example.com", Port: "993"}},
+		Concurrency: 4,
+		Proxies:     ProxyMap{"*.internal": "direct"},
+		WarnDays:    21,
+		NotifyDays:  []int{14, 3},
+		HTTP:        &HTTPSink{URL: "https://hec.example.com/services/collector", Backoff: Duration(2 * time.Second)},
+	}
+	for name, s := range map[string]string{"cert.yaml": yaml, "cert.yml": yaml, "cert.toml": toml} {
+		c, err := LoadConfig(writeConfigFile(t, name, s))
+		if err != nil {
+			t.Fatalf(`unexpected err %s for %s, want nil`, err.Error(), name)
+		}
+		if !reflect.DeepEqual(c, want) {
+			t.Errorf(`unexpected config %+v for %s, want %+v`, c, name, want)
+		}
+	}
+
+	for name, s := range map[string]string{
+		"cert.yaml": "targets: [example.com",
+		"cert.yml":  "targets:\n  - port: 443",
+		"cert.toml": "concurrency = \"4\"",
+	} {
+		if _, err := LoadConfig(writeConfigFile(t, name, s)); err == nil {
+			t.Errorf(`unexpected nil for %s, want error`, name)
+		}
+	}
+}
+
+func TestLoadConfigError(t *testing.T) {
+	for _, s := range []string{
+		`{"targets": [{"port": "443"}]}`,
+		`{"outputs": [{"format": "xml", "file": "x"}]}`,
+		`targets: [example.com]`,
+		`{"http": {"url": "https://example.com", "backoff": "2"}}`,
+		`{"warnDays": -1}`,
+		`{"notifyDays": [14, -3]}`,
+	} {
+		if _, err := LoadConfig(writeConfig(t, s)); err == nil {
+			t.Errorf(`unexpected nil for %s, want error`, s)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if _, err := (&Config{MaxTLS: "9"}).Options(); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if _, err := (&Config{Timeout: "3"}).Options(); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if _, err := (&Config{NamingConvention: "("}).Options(); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if _, err := (&Config{DistrustedRoots: []DistrustedRoot{{Name: "Old Root", Date: "2025"}}}).Options(); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestConfigScanConcurrency(t *testing.T) {
+	var running, peak int32
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	c := &Config{Concurrency: 2}
+	for i := 0; i < 8; i++ {
+		c.Targets = append(c.Targets, Target{Host: "example.com"})
+	}
+	certs, err := c.Scan()
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(certs) != 8 {
+		t.Errorf(`unexpected %d certs, want 8`, len(certs))
+	}
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Errorf(`unexpected %d concurrent scans, want at most 2`, p)
+	}
+}
@@ -0,0 +1,26 @@
+package cert
+
+import (
+	"crypto/tls"
+	"net"
+)
+
+// NewCertFromConn builds a Cert from a TLS client connection established
+// elsewhere, completing the handshake first if necessary. DomainName is the
+// server name sent by the client.
+func NewCertFromConn(conn *tls.Conn) *Cert {
+	if err := conn.Handshake(); err != nil {
+		return errorCert(conn.ConnectionState().ServerName, err)
+	}
+	c := NewCertFromConnectionState(conn.ConnectionState())
+	if c.Error == "" {
+		c.IP, _, _ = net.SplitHostPort(conn.RemoteAddr().String())
+	}
+	return c
+}
+
+// NewCertFromConnectionState builds a Cert from the state of a completed
+// client handshake. IP and timings are left empty.
+func NewCertFromConnectionState(state tls.ConnectionState) *Cert {
+	return newCert(state.ServerName, &serverInfo{chain: state.PeerCertificates, curve: state.CurveID}, DefaultOptions())
+}
@@ -0,0 +1,53 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+)
+
+func TestNewCertFromConn(t *testing.T) {
+	host, port := startTLSServer(t, nil)
+	conn, err := tls.Dial("tcp", net.JoinHostPort(host, port), &tls.Config{
+		ServerName:         "example.com",
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	c := NewCertFromConn(conn)
+	if c.Error != "" {
+		t.Fatalf(`unexpected Cert.Error %q, want %q`, c.Error, "")
+	}
+	if c.DomainName != "example.com" {
+		t.Errorf(`unexpected Cert.DomainName %q, want %q`, c.DomainName, "example.com")
+	}
+	if c.IP != "127.0.0.1" {
+		t.Errorf(`unexpected Cert.IP %q, want %q`, c.IP, "127.0.0.1")
+	}
+	if len(c.SANs) == 0 || c.SANs[0] != "example.com" {
+		t.Errorf(`unexpected Cert.SANs %v, want example.com first`, c.SANs)
+	}
+}
+
+func TestNewCertFromConnectionState(t *testing.T) {
+	c := NewCertFromConnectionState(tls.ConnectionState{
+		ServerName: "example.com",
+		PeerCertificates: []*x509.Certificate{{
+			Issuer:  pkix.Name{CommonName: "CA for test"},
+			Subject: pkix.Name{CommonName: "example.com"},
+		}},
+	})
+	if c.Issuer != "CA for test" {
+		t.Errorf(`unexpected Cert.Issuer %q, want %q`, c.Issuer, "CA for test")
+	}
+
+	c = NewCertFromConnectionState(tls.ConnectionState{ServerName: "example.com"})
+	if c.Error != errNoPeerCertificates.Error() || c.Status != StatusError {
+		t.Errorf(`unexpected Cert.Error %q, want %q`, c.Error, errNoPeerCertificates.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CSRDiff is a field that differs between a certificate signing request
+// and the certificate issued for it.
+type CSRDiff struct {
+	Field string `json:"field"`
+	CSR   string `json:"csr"`
+	Cert  string `json:"cert"`
+}
+
+// ParseCSR parses the first certificate signing request of PEM data, such
+// as a .csr file.
+func ParseCSR(data []byte) (*x509.CertificateRequest, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("No certificate request found.")
+		}
+		if block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+			continue
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid certificate request: %v.", err)
+		}
+		return csr, nil
+	}
+}
+
+// CompareCSR returns the differences of subject, SANs and public key
+// between csr and cert, or nil if cert was issued as requested. SANs are
+// compared regardless of order and case.
+func CompareCSR(csr *x509.CertificateRequest, cert *x509.Certificate) []CSRDiff {
+	var diffs []CSRDiff
+	diff := func(field, want, got string) {
+		if want != got {
+			diffs = append(diffs, CSRDiff{Field: field, CSR: want, Cert: got})
+		}
+	}
+	diff("Subject", csr.Subject.String(), cert.Subject.String())
+	diff("SANs",
+		sanList(csr.DNSNames, csr.EmailAddresses, csr.IPAddresses, csr.URIs),
+		sanList(cert.DNSNames, cert.EmailAddresses, cert.IPAddresses, cert.URIs))
+	if !bytes.Equal(csr.RawSubjectPublicKeyInfo, cert.RawSubjectPublicKeyInfo) {
+		diffs = append(diffs, CSRDiff{Field: "PublicKey", CSR: spkiPin(csr.RawSubjectPublicKeyInfo), Cert: spkiPin(cert.RawSubjectPublicKeyInfo)})
+	}
+	return diffs
+}
+
+// sanList returns the SANs sorted, in lower case and comma separated.
+func sanList(dns, emails []string, ips []net.IP, uris []*url.URL) string {
+	var all []string
+	for _, name := range dns {
+		all = append(all, strings.ToLower(name))
+	}
+	for _, email := range emails {
+		all = append(all, strings.ToLower(email))
+	}
+	for _, ip := range ips {
+		all = append(all, ip.String())
+	}
+	for _, uri := range uris {
+		all = append(all, strings.ToLower(uri.String()))
+	}
+	sort.Strings(all)
+	return strings.Join(all, ", ")
+}
@@ -0,0 +1,81 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func testCSR(t *testing.T, key *ecdsa.PrivateKey, template *x509.CertificateRequest) []byte {
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestCompareCSR(t *testing.T) {
+	ca := newTestCA(t, "Test CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		DNSNames:    []string{"www.example.com", "example.com"},
+		IPAddresses: []net.IP{net.IPv4(192, 0, 2, 1)},
+	}, ca)
+
+	csr, err := ParseCSR(testCSR(t, leaf.key, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		DNSNames:    []string{"Example.com", "www.example.com"},
+		IPAddresses: []net.IP{net.IPv4(192, 0, 2, 1)},
+	}))
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if diffs := CompareCSR(csr, leaf.cert); diffs != nil {
+		t.Errorf(`unexpected diffs %+v, want nil`, diffs)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	csr, _ = ParseCSR(testCSR(t, otherKey, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "example.com", Organization: []string{"Example Inc."}},
+		DNSNames: []string{"example.com", "api.example.com"},
+	}))
+	want := []CSRDiff{
+		{"Subject", "CN=example.com,O=Example Inc.", "CN=example.com"},
+		{"SANs", "api.example.com, example.com", "192.0.2.1, example.com, www.example.com"},
+		{"PublicKey", spkiPin(csr.RawSubjectPublicKeyInfo), spkiPin(leaf.cert.RawSubjectPublicKeyInfo)},
+	}
+	if diffs := CompareCSR(csr, leaf.cert); !reflect.DeepEqual(diffs, want) {
+		t.Errorf(`unexpected diffs %+v, want %+v`, diffs, want)
+	}
+
+	stubChain(leaf.cert, ca.cert)
+	defer stubCert()
+	c := NewCertWithOptions("example.com", &Options{CSR: csr})
+	if !reflect.DeepEqual(c.CSRDiffs, want) {
+		t.Errorf(`unexpected diffs %+v, want %+v`, c.CSRDiffs, want)
+	}
+	n := 0
+	for _, f := range findings(c) {
+		if f.Rule == "csr-mismatch" {
+			n++
+		}
+	}
+	if n != len(want) {
+		t.Errorf(`unexpected csr-mismatch findings %d, want %d`, n, len(want))
+	}
+}
+
+func TestParseCSR(t *testing.T) {
+	if _, err := ParseCSR([]byte("no csr")); err == nil || err.Error() != "No certificate request found." {
+		t.Errorf(`unexpected err %v, want no certificate request`, err)
+	}
+	if _, err := ParseCSR(pem.EncodeToMemory(&pem.Block{Type: "NEW CERTIFICATE REQUEST", Bytes: []byte{1}})); err == nil {
+		t.Errorf(`unexpected err nil, want invalid certificate request`)
+	}
+}
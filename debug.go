@@ -0,0 +1,195 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// Debug makes every scan capture details of the TLS handshake into
+// Cert.Debug, as Options.Debug does for scans with those options.
+var Debug = false
+
+// Diagnostic describes what was offered and chosen during a handshake.
+type Diagnostic struct {
+	OfferedVersions     []string `json:"offeredVersions"`
+	OfferedCipherSuites []string `json:"offeredCipherSuites"`
+	Version             string   `json:"version"`
+	CipherSuite         string   `json:"cipherSuite"`
+	ServerExtensions    []string `json:"serverExtensions"`
+	CertificateCount    int      `json:"certificateCount"`
+}
+
+const maxRecorded = 64 * 1024
+
+// recordingConn keeps a copy of the first bytes sent and received on a
+// connection, which is enough to see both hello messages.
+type recordingConn struct {
+	net.Conn
+	read    bytes.Buffer
+	written bytes.Buffer
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if c.read.Len() < maxRecorded {
+		c.read.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	if c.written.Len() < maxRecorded {
+		c.written.Write(p)
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *recordingConn) diagnostic(state tls.ConnectionState) *Diagnostic {
+	d := &Diagnostic{
+		Version:          tls.VersionName(state.Version),
+		CipherSuite:      tls.CipherSuiteName(state.CipherSuite),
+		CertificateCount: len(state.PeerCertificates),
+	}
+	if ch, err := parseHello(c.written.Bytes(), 1); err == nil {
+		for _, v := range ch.versions {
+			d.OfferedVersions = append(d.OfferedVersions, tls.VersionName(v))
+		}
+		for _, s := range ch.cipherSuites {
+			d.OfferedCipherSuites = append(d.OfferedCipherSuites, tls.CipherSuiteName(s))
+		}
+	}
+	if sh, err := parseHello(c.read.Bytes(), 2); err == nil {
+		for _, e := range sh.extensions {
+			d.ServerExtensions = append(d.ServerExtensions, extensionName(e))
+		}
+	}
+	return d
+}
+
+type hello struct {
+	version      uint16
+	versions     []uint16
+	cipherSuites []uint16
+	extensions   []uint16
+}
+
+// parseHello parses the first handshake message of type msgType
+// (1: ClientHello, 2: ServerHello) out of a stream of TLS records.
+func parseHello(stream []byte, msgType byte) (*hello, error) {
+	var msg []byte
+	for len(stream) >= 5 && stream[0] == 22 {
+		n := int(binary.BigEndian.Uint16(stream[3:5]))
+		if len(stream) < 5+n {
+			break
+		}
+		msg = append(msg, stream[5:5+n]...)
+		stream = stream[5+n:]
+	}
+	if len(msg) < 4 || msg[0] != msgType {
+		return nil, fmt.Errorf("no hello message of type %d", msgType)
+	}
+	n := int(msg[1])<<16 | int(msg[2])<<8 | int(msg[3])
+	if len(msg) < 4+n {
+		return nil, fmt.Errorf("truncated hello message")
+	}
+	r := reader(msg[4 : 4+n])
+
+	h := &hello{}
+	h.version = r.uint16()
+	r.skip(32)
+	r.skip(int(r.uint8()))
+	if msgType == 1 {
+		suites := reader(r.bytes(int(r.uint16())))
+		for len(suites) >= 2 {
+			h.cipherSuites = append(h.cipherSuites, suites.uint16())
+		}
+		r.skip(int(r.uint8()))
+	} else {
+		h.cipherSuites = []uint16{r.uint16()}
+		r.skip(1)
+	}
+	exts := reader(r.bytes(int(r.uint16())))
+	for len(exts) >= 4 {
+		typ := exts.uint16()
+		data := reader(exts.bytes(int(exts.uint16())))
+		h.extensions = append(h.extensions, typ)
+		if typ != 43 {
+			continue
+		}
+		if msgType == 1 {
+			list := reader(data.bytes(int(data.uint8())))
+			for len(list) >= 2 {
+				h.versions = append(h.versions, list.uint16())
+			}
+		} else {
+			h.versions = []uint16{data.uint16()}
+		}
+	}
+	if len(h.versions) == 0 {
+		h.versions = []uint16{h.version}
+	}
+	if r == nil {
+		return nil, fmt.Errorf("malformed hello message")
+	}
+	return h, nil
+}
+
+// reader consumes big-endian fields; on a short read it becomes nil and
+// every further read yields zero values.
+type reader []byte
+
+func (r *reader) bytes(n int) []byte {
+	if n > len(*r) {
+		*r = nil
+		return nil
+	}
+	b := (*r)[:n]
+	*r = (*r)[n:]
+	return b
+}
+
+func (r *reader) skip(n int) { r.bytes(n) }
+
+func (r *reader) uint8() uint8 {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *reader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+var extensionNames = map[uint16]string{
+	0:     "server_name",
+	5:     "status_request",
+	10:    "supported_groups",
+	11:    "ec_point_formats",
+	13:    "signature_algorithms",
+	16:    "application_layer_protocol_negotiation",
+	18:    "signed_certificate_timestamp",
+	23:    "extended_master_secret",
+	35:    "session_ticket",
+	41:    "pre_shared_key",
+	43:    "supported_versions",
+	44:    "cookie",
+	45:    "psk_key_exchange_modes",
+	51:    "key_share",
+	65281: "renegotiation_info",
+}
+
+func extensionName(typ uint16) string {
+	if name, ok := extensionNames[typ]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", typ)
+}
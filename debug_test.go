@@ -0,0 +1,61 @@
+package cert
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestServerCertWithDebug(t *testing.T) {
+	Debug = true
+	defer func() { Debug = false }()
+
+	host, port := startTLSServer(t, nil)
+	info, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	d := info.diagnostic
+	if d == nil {
+		t.Fatal(`unexpected nil diagnostic`)
+	}
+	if d.Version != "TLS 1.3" {
+		t.Errorf(`unexpected Version %q, want %q`, d.Version, "TLS 1.3")
+	}
+	if len(d.OfferedVersions) == 0 || d.OfferedVersions[0] != "TLS 1.3" {
+		t.Errorf(`unexpected OfferedVersions %v, want TLS 1.3 first`, d.OfferedVersions)
+	}
+	if len(d.OfferedCipherSuites) == 0 {
+		t.Error(`unexpected empty OfferedCipherSuites`)
+	}
+	if !reflect.DeepEqual(d.ServerExtensions, []string{"key_share", "supported_versions"}) &&
+		!reflect.DeepEqual(d.ServerExtensions, []string{"supported_versions", "key_share"}) {
+		t.Errorf(`unexpected ServerExtensions %v, want key_share and supported_versions`, d.ServerExtensions)
+	}
+	if d.CertificateCount != 1 {
+		t.Errorf(`unexpected CertificateCount %d, want %d`, d.CertificateCount, 1)
+	}
+}
+
+func TestParseHelloError(t *testing.T) {
+	var tests = [][]byte{
+		nil,
+		{22, 3, 1, 0, 4, 2, 0, 0, 0},
+		{21, 3, 3, 0, 2, 2, 40},
+	}
+	for _, test := range tests {
+		if _, err := parseHello(test, 1); err == nil {
+			t.Errorf(`parseHello(%v) unexpected nil, want error`, test)
+		}
+	}
+}
+
+func TestExtensionName(t *testing.T) {
+	if name := extensionName(43); name != "supported_versions" {
+		t.Errorf(`unexpected name %q, want %q`, name, "supported_versions")
+	}
+	if name := extensionName(4242); name != "unknown(4242)" {
+		t.Errorf(`unexpected name %q, want %q`, name, "unknown(4242)")
+	}
+}
@@ -0,0 +1,216 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultFallbackDelay is the Connection Attempt Delay recommended by
+// RFC 8305.
+const defaultFallbackDelay = 250 * time.Millisecond
+
+// dialAny connects to one of addrs. Addresses are tried in the order of
+// interleave; an attempt that has not finished after opts.FallbackDelay is
+// raced by the next one, and a failed attempt starts the next one at once.
+// The first connection wins and the others are cancelled.
+func dialAny(ctx context.Context, addrs []net.IPAddr, port string, opts *Options) (net.Conn, error) {
+	opts = opts.orDefault()
+	if len(addrs) == 0 {
+		return nil, errNoAddresses
+	}
+	delay := opts.FallbackDelay
+	if delay == 0 {
+		delay = defaultFallbackDelay
+	}
+	locals, err := localIPs(opts.LocalAddr)
+	if err != nil {
+		return nil, err
+	}
+	addrs = interleave(addrs)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan result, len(addrs))
+	next, pending := 0, 0
+	start := func() {
+		remote := addrs[next].IP
+		hostport := net.JoinHostPort(addrs[next].String(), port)
+		next++
+		pending++
+		opts.logDebug("dialing", "addr", hostport)
+		go func() {
+			var d net.Dialer
+			if locals != nil {
+				local := sameFamily(locals, remote)
+				if local == nil {
+					results <- result{nil, fmt.Errorf("No local address of the family of %s.", remote)}
+					return
+				}
+				d.LocalAddr = &net.TCPAddr{IP: local}
+			}
+			conn, err := d.DialContext(ctx, "tcp", hostport)
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					opts.logDebug("dial timeout", "addr", hostport, "err", err)
+				} else {
+					opts.logDebug("dial failed", "addr", hostport, "err", err)
+				}
+			}
+			results <- result{conn, err}
+		}()
+	}
+
+	var timer *time.Timer
+	var fallback <-chan time.Time
+	if delay > 0 {
+		timer = time.NewTimer(delay)
+		defer timer.Stop()
+		fallback = timer.C
+	}
+
+	var firstErr error
+	start()
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				go func(n int) {
+					for ; n > 0; n-- {
+						if r := <-results; r.conn != nil {
+							r.conn.Close()
+						}
+					}
+				}(pending)
+				return r.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			if next < len(addrs) {
+				start()
+				if timer != nil {
+					timer.Reset(delay)
+				}
+			}
+		case <-fallback:
+			if next < len(addrs) {
+				start()
+				timer.Reset(delay)
+			}
+		}
+	}
+	return nil, firstErr
+}
+
+// interleave orders addrs by alternating address families, starting with
+// the family of the first address, as RFC 8305 section 4 recommends.
+func interleave(addrs []net.IPAddr) []net.IPAddr {
+	var first, second []net.IPAddr
+	isV4 := addrs[0].IP.To4() != nil
+	for _, a := range addrs {
+		if (a.IP.To4() != nil) == isV4 {
+			first = append(first, a)
+		} else {
+			second = append(second, a)
+		}
+	}
+	out := make([]net.IPAddr, 0, len(addrs))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			out = append(out, first[i])
+		}
+		if i < len(second) {
+			out = append(out, second[i])
+		}
+	}
+	return out
+}
+
+// localIPs returns the IPs of addr, an IP address or the name of a network
+// interface, or nil if addr is empty.
+func localIPs(addr string) ([]net.IP, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	iface, err := net.InterfaceByName(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown local address %q.", addr)
+	}
+	ifaddrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, a := range ifaddrs {
+		if n, ok := a.(*net.IPNet); ok && !n.IP.IsLinkLocalUnicast() {
+			ips = append(ips, n.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("Interface %q has no address.", addr)
+	}
+	return ips, nil
+}
+
+// sameFamily returns the first of ips in the address family of remote.
+func sameFamily(ips []net.IP, remote net.IP) net.IP {
+	for _, ip := range ips {
+		if (ip.To4() != nil) == (remote.To4() != nil) {
+			return ip
+		}
+	}
+	return nil
+}
+
+// dialTarget connects to host and port the way scans do, through the proxy
+// opts.Proxy returns for host or else directly with dialAny. proxied
+// reports whether a proxy was used, so the remote address is not host's.
+func dialTarget(ctx context.Context, host, port string, opts *Options) (conn net.Conn, proxied bool, err error) {
+	if opts.Proxy != nil {
+		proxy, err := opts.Proxy(host)
+		if err != nil {
+			return nil, false, err
+		}
+		if proxy != nil {
+			conn, err := dialProxy(ctx, proxy, net.JoinHostPort(host, port), opts)
+			return conn, true, err
+		}
+	}
+	conn, err = opts.dialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	return conn, false, err
+}
+
+// httpProxy returns the proxy of opts.Proxy for req, for use as
+// http.Transport.Proxy.
+func (opts *Options) httpProxy(req *http.Request) (*url.URL, error) {
+	if opts.Proxy == nil {
+		return nil, nil
+	}
+	return opts.Proxy(req.URL.Hostname())
+}
+
+// dialContext resolves addr and connects to it with dialAny, for use as
+// http.Transport.DialContext.
+func (opts *Options) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := opts.resolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return dialAny(ctx, addrs, port, opts)
+}
@@ -0,0 +1,111 @@
+package cert
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInterleave(t *testing.T) {
+	v6a, v6b := net.IPAddr{IP: net.ParseIP("2001:db8::1")}, net.IPAddr{IP: net.ParseIP("2001:db8::2")}
+	v4a, v4b := net.IPAddr{IP: net.ParseIP("192.0.2.1")}, net.IPAddr{IP: net.ParseIP("192.0.2.2")}
+
+	var tests = []struct {
+		in, want []net.IPAddr
+	}{
+		{[]net.IPAddr{v6a, v6b, v4a, v4b}, []net.IPAddr{v6a, v4a, v6b, v4b}},
+		{[]net.IPAddr{v4a, v6a, v6b}, []net.IPAddr{v4a, v6a, v6b}},
+		{[]net.IPAddr{v4a, v4b}, []net.IPAddr{v4a, v4b}},
+	}
+	for _, test := range tests {
+		if got := interleave(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf(`unexpected order %v, want %v`, got, test.want)
+		}
+	}
+}
+
+func TestDialAnyFallback(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	// 192.0.2.1 (TEST-NET-1) is not routable, so dialing it stalls or fails.
+	addrs := []net.IPAddr{{IP: net.ParseIP("192.0.2.1")}, {IP: net.ParseIP("127.0.0.1")}}
+
+	start := time.Now()
+	conn, err := dialAny(context.Background(), addrs, port, &Options{FallbackDelay: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf(`unexpected elapsed %s, want the second address raced after the delay`, elapsed)
+	}
+	if got := conn.RemoteAddr().String(); got != "127.0.0.1:"+port {
+		t.Errorf(`unexpected remote address %s, want 127.0.0.1:%s`, got, port)
+	}
+}
+
+func TestDialAnyAllFail(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	addrs := []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}, {IP: net.ParseIP("127.0.0.1")}}
+	for _, delay := range []time.Duration{0, -1} {
+		if _, err := dialAny(context.Background(), addrs, port, &Options{FallbackDelay: delay}); err == nil || errorKind(err) != ErrorKindRefused {
+			t.Errorf(`unexpected err %v with delay %s, want connection refused`, err, delay)
+		}
+	}
+	if _, err := dialAny(context.Background(), nil, port, nil); err != errNoAddresses {
+		t.Errorf(`unexpected err %v, want %v`, err, errNoAddresses)
+	}
+}
+
+func TestDialAnyLocalAddr(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	addrs := []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}
+
+	conn, err := dialAny(context.Background(), addrs, port, &Options{LocalAddr: "127.0.0.2"})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	host, _, _ := net.SplitHostPort(conn.LocalAddr().String())
+	conn.Close()
+	if host != "127.0.0.2" {
+		t.Errorf(`unexpected local address %s, want 127.0.0.2`, host)
+	}
+
+	if _, err := dialAny(context.Background(), addrs, port, &Options{LocalAddr: "::1"}); err == nil {
+		t.Error(`unexpected nil, want error for mismatched address family`)
+	}
+	if _, err := dialAny(context.Background(), addrs, port, &Options{LocalAddr: "no-such-interface0"}); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestLocalIPs(t *testing.T) {
+	ips, err := localIPs("lo")
+	if err != nil {
+		t.Skipf(`no loopback interface named lo: %s`, err)
+	}
+	if sameFamily(ips, net.ParseIP("127.0.0.1")) == nil {
+		t.Errorf(`unexpected addresses %v of lo, want an IPv4 address`, ips)
+	}
+	if ips, err := localIPs(""); ips != nil || err != nil {
+		t.Errorf(`unexpected %v, %v, want nil, nil`, ips, err)
+	}
+}
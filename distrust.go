@@ -0,0 +1,116 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DistrustedRoot is a root or cross-signed intermediate that clients stop
+// trusting, or already have.
+type DistrustedRoot struct {
+	// Name is its subject common name.
+	Name string `json:"name"`
+	// Date is the day clients stop trusting it, as 2006-01-02.
+	Date string `json:"date"`
+	// Reason tells operators which clients break and why.
+	Reason string `json:"reason,omitempty"`
+	// SPKI, if set, is the SPKI pin of its public key, "sha256/" and the
+	// base64 of its SHA-256, to tell it from other certificates of the
+	// same name.
+	SPKI string `json:"spki,omitempty"`
+}
+
+// DefaultDistrustedRoots are well known roots that browsers and operating
+// systems have distrusted or announced to distrust, and expired roots that
+// cross-signed chains still lead to.
+var DefaultDistrustedRoots = []DistrustedRoot{
+	{"DST Root CA X3", "2021-09-30", "expired, old clients fail to build another path to ISRG Root X1", ""},
+	{"VeriSign Class 3 Public Primary Certification Authority - G5", "2018-10-23", "Symantec roots are distrusted by Chrome and Firefox", ""},
+	{"GeoTrust Global CA", "2018-10-23", "Symantec roots are distrusted by Chrome and Firefox", ""},
+	{"Entrust Root Certification Authority", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/bb+uANN7nNc/j7R95lkXrwDg3d9C286sIMF8AnXuIJU="},
+	{"Entrust Root Certification Authority - G2", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/du6FkDdMcVQ3u8prumAo6t3i3G27uMP2EOhR8R0at/U="},
+	{"Entrust Root Certification Authority - EC1", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256//qK31kX7pz11PB7Jp4cMQOH3sMVh6Se5hb9xGGbjbyI="},
+	{"Entrust.net Certification Authority (2048)", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/HqPF5D7WbC2imDpCpKebHpBnhs6fG1hiFBmgBGOofTg="},
+	{"AffirmTrust Commercial", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/bEZLmlsjOl6HTadlwm8EUBDS3c/0V5TwtMfkqvpQFJU="},
+	{"AffirmTrust Networking", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/lAcq0/WPcPkwmOWl9sBMlscQvYSdgxhJGa6Q64kK5AA="},
+	{"AffirmTrust Premium", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/x/Q7TPW3FWgpT4IrU3YmBfbd0Vyt7Oc56eLDy6YenWc="},
+	{"AffirmTrust Premium ECC", "2024-11-11", "Chrome and Firefox distrust certificates issued later", "sha256/MhmwkRT/SVo+tusAwu/qs0ACrl8KVsdnnqCHo/oDfk8="},
+}
+
+// LoadDistrustedRoots reads a JSON list of DistrustedRoot from the file at
+// path.
+func LoadDistrustedRoots(path string) ([]DistrustedRoot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var roots []DistrustedRoot
+	if err := json.Unmarshal(data, &roots); err != nil {
+		return nil, fmt.Errorf("Invalid distrusted roots %s: %v.", path, err)
+	}
+	if err := validateDistrustedRoots(roots, path); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// validateDistrustedRoots checks the roots read from source.
+func validateDistrustedRoots(roots []DistrustedRoot, source string) error {
+	for _, r := range roots {
+		if r.Name == "" {
+			return fmt.Errorf("Distrusted root without name in %s.", source)
+		}
+		if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+			return fmt.Errorf("Invalid date %q of distrusted root %s.", r.Date, r.Name)
+		}
+		if r.SPKI != "" && (!strings.HasPrefix(r.SPKI, "sha256/") || validatePin(r.SPKI) != nil) {
+			return fmt.Errorf("Invalid SPKI pin %q of distrusted root %s.", r.SPKI, r.Name)
+		}
+	}
+	return nil
+}
+
+// DistrustedRoots reports chains that end in one of roots, sent or as the
+// issuer of the last certificate sent, as rule "distrusted-root", both
+// before and after the date so that operators get advance notice. Sent
+// certificates match by the SPKI of a root if it has one and by name
+// otherwise, the issuer not sent by name.
+func DistrustedRoots(roots ...DistrustedRoot) Checker {
+	return CheckerFunc(func(c *Cert, leaf *x509.Certificate) []Finding {
+		names, spkis := map[string]bool{}, map[string]bool{}
+		for _, cert := range c.chain[1:] {
+			names[cert.Subject.CommonName] = true
+			spkis[spkiPin(cert.RawSubjectPublicKeyInfo)] = true
+		}
+		// A self-issued last certificate is the root, no other was left out.
+		issuer := ""
+		if last := c.chain[len(c.chain)-1]; last.Issuer.String() != last.Subject.String() {
+			issuer = last.Issuer.CommonName
+		}
+
+		var fs []Finding
+		for _, r := range roots {
+			sent := names[r.Name]
+			if r.SPKI != "" {
+				sent = spkis[r.SPKI]
+			}
+			date, err := time.Parse("2006-01-02", r.Date)
+			if !sent && (issuer == "" || issuer != r.Name) || err != nil {
+				continue
+			}
+			msg := fmt.Sprintf("Chain ends in %s, distrusted since %s", r.Name, r.Date)
+			if date.After(now()) {
+				msg = fmt.Sprintf("Chain ends in %s, to be distrusted on %s", r.Name, r.Date)
+			}
+			if r.Reason != "" {
+				msg += ": " + r.Reason
+			}
+			fs = append(fs, Finding{Rule: "distrusted-root", Severity: "warning", Message: msg + "."})
+		}
+		return fs
+	})
+}
@@ -0,0 +1,92 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDistrustedRoots(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}, Issuer: pkix.Name{CommonName: "R3"}}
+	r3 := &x509.Certificate{Subject: pkix.Name{CommonName: "R3"}, Issuer: pkix.Name{CommonName: "ISRG Root X1"}}
+	cross := &x509.Certificate{Subject: pkix.Name{CommonName: "ISRG Root X1"}, Issuer: pkix.Name{CommonName: "DST Root CA X3"}}
+	entrust := &x509.Certificate{Subject: pkix.Name{CommonName: "Entrust Certification Authority - L1K"}, Issuer: pkix.Name{CommonName: "Entrust Root Certification Authority - G2"}}
+
+	checker := DistrustedRoots(DefaultDistrustedRoots...)
+	tests := []struct {
+		chain []*x509.Certificate
+		want  []Finding
+	}{
+		{[]*x509.Certificate{leaf, r3}, nil},
+		{[]*x509.Certificate{leaf, r3, cross}, []Finding{{"distrusted-root", "warning", "Chain ends in DST Root CA X3, distrusted since 2021-09-30: expired, old clients fail to build another path to ISRG Root X1."}}},
+		{[]*x509.Certificate{leaf, entrust}, []Finding{{"distrusted-root", "warning", "Chain ends in Entrust Root Certification Authority - G2, to be distrusted on 2024-11-11: Chrome and Firefox distrust certificates issued later."}}},
+	}
+	for _, tt := range tests {
+		if got := checker.Check(&Cert{chain: tt.chain}, leaf); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf(`unexpected findings %+v, want %+v`, got, tt.want)
+		}
+	}
+
+	got := DistrustedRoots(DistrustedRoot{Name: "R3", Date: "2025-01-01"}).Check(&Cert{chain: []*x509.Certificate{leaf}}, leaf)
+	want := []Finding{{"distrusted-root", "warning", "Chain ends in R3, to be distrusted on 2025-01-01."}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected findings %+v, want %+v`, got, want)
+	}
+}
+
+func TestDistrustedRootsSPKI(t *testing.T) {
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}, Issuer: pkix.Name{CommonName: "AffirmTrust Commercial"}}
+	// A root of the same name but another key, e.g. a private CA.
+	namesake := &x509.Certificate{Subject: pkix.Name{CommonName: "AffirmTrust Commercial"}, Issuer: pkix.Name{CommonName: "AffirmTrust Commercial"}, RawSubjectPublicKeyInfo: []byte("other key")}
+	if got := DistrustedRoots(DefaultDistrustedRoots...).Check(&Cert{chain: []*x509.Certificate{leaf, namesake}}, leaf); got != nil {
+		t.Errorf(`unexpected findings %+v, want none`, got)
+	}
+
+	root := &x509.Certificate{Subject: pkix.Name{CommonName: "Renamed Root"}, Issuer: pkix.Name{CommonName: "Renamed Root"}, RawSubjectPublicKeyInfo: []byte("old key")}
+	checker := DistrustedRoots(DistrustedRoot{Name: "Old Root", Date: "2020-01-01", SPKI: spkiPin([]byte("old key"))})
+	want := []Finding{{"distrusted-root", "warning", "Chain ends in Old Root, distrusted since 2020-01-01."}}
+	if got := checker.Check(&Cert{chain: []*x509.Certificate{leaf, root}}, leaf); !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected findings %+v, want %+v`, got, want)
+	}
+}
+
+func TestLoadDistrustedRoots(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	roots, err := LoadDistrustedRoots(write("ok.json", `[{"name": "Old Root", "date": "2025-06-30", "reason": "retired"}]`))
+	want := []DistrustedRoot{{"Old Root", "2025-06-30", "retired", ""}}
+	if err != nil || !reflect.DeepEqual(roots, want) {
+		t.Errorf(`unexpected roots %+v and err %v, want %+v`, roots, err, want)
+	}
+
+	tests := []struct {
+		data, err string
+	}{
+		{`[{"date": "2025-06-30"}]`, "Distrusted root without name in "},
+		{`[{"name": "Old Root", "date": "June 2025"}]`, `Invalid date "June 2025" of distrusted root Old Root.`},
+		{`[{"name": "Old Root", "date": "2025-06-30", "spki": "abc"}]`, `Invalid SPKI pin "abc" of distrusted root Old Root.`},
+	}
+	for _, tt := range tests {
+		path := write("bad.json", tt.data)
+		if _, err := LoadDistrustedRoots(path); err == nil || err.Error() != tt.err && err.Error() != tt.err+path+"." {
+			t.Errorf(`unexpected err %v, want %q`, err, tt.err)
+		}
+	}
+	if _, err := LoadDistrustedRoots(write("invalid.json", `{`)); err == nil {
+		t.Errorf(`unexpected err nil, want invalid JSON`)
+	}
+}
@@ -0,0 +1,62 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"strconv"
+)
+
+// DOT renders hosts, their certificates and the issuers above them as a
+// Graphviz digraph. Certificates shared by several hosts appear once.
+func (certs Certs) DOT() string {
+	var b bytes.Buffer
+	seen := map[string]bool{}
+	node := func(id, label, attrs string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", strconv.Quote(id), strconv.Quote(label), attrs)
+	}
+	edge := func(from, to string) {
+		key := from + "->" + to
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		fmt.Fprintf(&b, "  %s -> %s;\n", strconv.Quote(from), strconv.Quote(to))
+	}
+
+	b.WriteString("digraph certs {\n  rankdir=LR;\n")
+	for _, cert := range certs {
+		host := "host:" + cert.DomainName
+		node(host, cert.DomainName, ", shape=box")
+		if len(cert.chain) == 0 {
+			continue
+		}
+		prev := host
+		for _, c := range cert.chain {
+			id := certID(c)
+			node(id, c.Subject.CommonName, ", shape=ellipse")
+			edge(prev, id)
+			prev = id
+		}
+		last := cert.chain[len(cert.chain)-1]
+		if last.Issuer.String() != last.Subject.String() {
+			id := "issuer:" + last.Issuer.String()
+			node(id, last.Issuer.CommonName, ", shape=ellipse, style=dashed")
+			edge(prev, id)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func certID(c *x509.Certificate) string {
+	if len(c.Raw) == 0 {
+		return "cert:" + c.Subject.String()
+	}
+	return fmt.Sprintf("cert:%x", sha256.Sum256(c.Raw))
+}
@@ -0,0 +1,45 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+)
+
+func TestCertsAsDOT(t *testing.T) {
+	intermediate := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "Intermediate CA"},
+		Issuer:  pkix.Name{CommonName: "Root CA"},
+	}
+	leaf := func(host string) *x509.Certificate {
+		return &x509.Certificate{
+			Subject: pkix.Name{CommonName: host},
+			Issuer:  pkix.Name{CommonName: "Intermediate CA"},
+		}
+	}
+	certs := Certs{
+		{DomainName: "example.com", chain: []*x509.Certificate{leaf("example.com"), intermediate}},
+		{DomainName: "example.org", chain: []*x509.Certificate{leaf("example.org"), intermediate}},
+		{DomainName: "example.net", Error: "connection refused"},
+	}
+
+	expected := `digraph certs {
+  rankdir=LR;
+  "host:example.com" [label="example.com", shape=box];
+  "cert:CN=example.com" [label="example.com", shape=ellipse];
+  "host:example.com" -> "cert:CN=example.com";
+  "cert:CN=Intermediate CA" [label="Intermediate CA", shape=ellipse];
+  "cert:CN=example.com" -> "cert:CN=Intermediate CA";
+  "issuer:CN=Root CA" [label="Root CA", shape=ellipse, style=dashed];
+  "cert:CN=Intermediate CA" -> "issuer:CN=Root CA";
+  "host:example.org" [label="example.org", shape=box];
+  "cert:CN=example.org" [label="example.org", shape=ellipse];
+  "host:example.org" -> "cert:CN=example.org";
+  "cert:CN=example.org" -> "cert:CN=Intermediate CA";
+  "host:example.net" [label="example.net", shape=box];
+}
+`
+	if certs.DOT() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, certs.DOT(), expected)
+	}
+}
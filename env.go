@@ -0,0 +1,54 @@
+package cert
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ApplyEnv overrides opts with those of the following environment variables
+// that are set:
+//
+//	CERT_TIMEOUT               Timeout, e.g. 10s
+//	CERT_CONCURRENCY           Concurrency
+//	CERT_INSECURE_SKIP_VERIFY  InsecureSkipVerify, e.g. true
+//	CERT_MIN_TLS, CERT_MAX_TLS MinVersion and MaxVersion, e.g. 1.2
+//	CERT_PROXY                 Proxy of every target, e.g. http://proxy:3128 or direct
+//
+// HTTPS_PROXY and NO_PROXY are read by ProxyFromEnvironment.
+func (opts *Options) ApplyEnv() error {
+	var err error
+	if v, ok := os.LookupEnv("CERT_TIMEOUT"); ok {
+		if opts.Timeout, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("Invalid CERT_TIMEOUT %q.", v)
+		}
+	}
+	if v, ok := os.LookupEnv("CERT_CONCURRENCY"); ok {
+		if opts.Concurrency, err = strconv.Atoi(v); err != nil || opts.Concurrency < 0 {
+			return fmt.Errorf("Invalid CERT_CONCURRENCY %q.", v)
+		}
+	}
+	if v, ok := os.LookupEnv("CERT_INSECURE_SKIP_VERIFY"); ok {
+		if opts.InsecureSkipVerify, err = strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("Invalid CERT_INSECURE_SKIP_VERIFY %q.", v)
+		}
+	}
+	if v, ok := os.LookupEnv("CERT_MIN_TLS"); ok {
+		if opts.MinVersion, err = ParseTLSVersion(v); err != nil {
+			return err
+		}
+	}
+	if v, ok := os.LookupEnv("CERT_MAX_TLS"); ok {
+		if opts.MaxVersion, err = ParseTLSVersion(v); err != nil {
+			return err
+		}
+	}
+	if v, ok := os.LookupEnv("CERT_PROXY"); ok {
+		if _, err := parseProxy(v); err != nil {
+			return err
+		}
+		opts.Proxy = ProxyMap{"*": v}.Proxy
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cert
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestOptionsApplyEnv(t *testing.T) {
+	t.Setenv("CERT_TIMEOUT", "5s")
+	t.Setenv("CERT_CONCURRENCY", "8")
+	t.Setenv("CERT_INSECURE_SKIP_VERIFY", "true")
+	t.Setenv("CERT_MIN_TLS", "1.2")
+
+	opts := &Options{MaxVersion: tls.VersionTLS13}
+	if err := opts.ApplyEnv(); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if opts.Timeout != 5*time.Second || opts.Concurrency != 8 || !opts.InsecureSkipVerify || opts.MinVersion != tls.VersionTLS12 || opts.MaxVersion != tls.VersionTLS13 {
+		t.Errorf(`unexpected options %+v`, opts)
+	}
+
+	t.Setenv("CERT_PROXY", "http://proxy:3128")
+	if err := opts.ApplyEnv(); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if u, _ := opts.Proxy("example.com"); u == nil || u.Host != "proxy:3128" {
+		t.Errorf(`unexpected proxy %v, want proxy:3128`, u)
+	}
+
+	for name, value := range map[string]string{
+		"CERT_TIMEOUT":              "5",
+		"CERT_CONCURRENCY":          "-1",
+		"CERT_INSECURE_SKIP_VERIFY": "maybe",
+		"CERT_MAX_TLS":              "2.0",
+		"CERT_PROXY":                "socks5://proxy:1080",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if err := (&Options{}).ApplyEnv(); err == nil {
+				t.Errorf(`unexpected nil for %s=%s, want error`, name, value)
+			}
+		})
+	}
+}
+
+func TestOptionsTimeout(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	// A server that accepts but never answers the handshake.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	start := time.Now()
+	c := NewCertWithOptions(host+":"+port, &Options{Timeout: 100 * time.Millisecond})
+	if c.Error == "" || c.ErrorKind != ErrorKindTimeout {
+		t.Errorf(`unexpected Cert.Error %q (%s), want timeout`, c.Error, c.ErrorKind)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf(`unexpected elapsed %s, want about 100ms`, elapsed)
+	}
+}
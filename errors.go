@@ -0,0 +1,56 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+const (
+	ErrorKindDNS      = "dns"
+	ErrorKindRefused  = "refused"
+	ErrorKindTimeout  = "timeout"
+	ErrorKindTLS      = "tls"
+	ErrorKindProtocol = "protocol"
+	ErrorKindOther    = "other"
+)
+
+var (
+	errNoAddresses        = errors.New("no addresses found")
+	errNoPeerCertificates = errors.New("no peer certificates")
+)
+
+// errorKind sorts err into one of the ErrorKind categories.
+func errorKind(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var verifyErr *tls.CertificateVerificationError
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &dnsErr), errors.Is(err, errNoAddresses):
+		return ErrorKindDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorKindRefused
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindTimeout
+	case errors.As(err, &recordErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, errNoPeerCertificates):
+		return ErrorKindProtocol
+	case errors.As(err, &alertErr), errors.As(err, &verifyErr),
+		errors.As(err, &hostErr), errors.As(err, &authErr), errors.As(err, &invalidErr),
+		strings.Contains(err.Error(), "tls: "):
+		return ErrorKindTLS
+	}
+	return ErrorKindOther
+}
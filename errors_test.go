@@ -0,0 +1,53 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestErrorKind(t *testing.T) {
+	var tests = []struct {
+		err  error
+		want string
+	}{
+		{&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, ErrorKindDNS},
+		{errNoAddresses, ErrorKindDNS},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, ErrorKindRefused},
+		{fmt.Errorf("dial: %w", context.DeadlineExceeded), ErrorKindTimeout},
+		{&net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, ErrorKindTimeout},
+		{tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, ErrorKindProtocol},
+		{&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, ErrorKindTLS},
+		{tls.AlertError(40), ErrorKindTLS},
+		{errors.New("tls: handshake failure"), ErrorKindTLS},
+		{errors.New("something else"), ErrorKindOther},
+	}
+	for _, test := range tests {
+		if got := errorKind(test.err); got != test.want {
+			t.Errorf("errorKind(%v) = %q, want %q", test.err, got, test.want)
+		}
+	}
+}
+
+func TestNewCertRefused(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	c := NewCert(addr)
+	if c.ErrorKind != ErrorKindRefused {
+		t.Errorf(`unexpected Cert.ErrorKind %q, want %q`, c.ErrorKind, ErrorKindRefused)
+	}
+}
@@ -0,0 +1,56 @@
+package cert
+
+import "time"
+
+const (
+	ExitOK       = 0
+	ExitWarning  = 1
+	ExitCritical = 2
+)
+
+// ExitPolicy controls the result of Certs.ExitCode.
+type ExitPolicy struct {
+	// Warning is how long before expiry a certificate raises ExitWarning.
+	Warning time.Duration
+}
+
+// ExitCode returns ExitCritical if any cert errored or has expired,
+// ExitWarning if any cert expires within policy.Warning and ExitOK otherwise.
+// The severities of Cert.Policy apply: a failure or expiry set to "warning"
+// raises ExitWarning only and one set to "ignore" nothing.
+func (certs Certs) ExitCode(policy ExitPolicy) int {
+	code := ExitOK
+	raise := func(severity string) {
+		switch severity {
+		case "error":
+			code = ExitCritical
+		case "warning":
+			if code < ExitWarning {
+				code = ExitWarning
+			}
+		}
+	}
+	for _, cert := range certs {
+		if cert.Error != "" {
+			raise(cert.severity(errorRule(cert)))
+		} else if notAfter, ok := cert.expiry(); ok {
+			if now().After(notAfter) {
+				raise(cert.severity("expired"))
+			} else if now().Add(policy.Warning).After(notAfter) {
+				raise(cert.severity("expiring"))
+			}
+		}
+		if code == ExitCritical {
+			return code
+		}
+	}
+	return code
+}
+
+func (c *Cert) expiry() (time.Time, bool) {
+	if !c.notAfter.IsZero() {
+		return c.notAfter, true
+	}
+	t, err := parseTime(c.NotAfter)
+	return t, err == nil
+}
@@ -0,0 +1,62 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertsExitCode(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	ok := &Cert{DomainName: "ok.example.com", notAfter: time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	expiring := &Cert{DomainName: "expiring.example.com", NotAfter: "2018-01-10 00:00:00 +0000 UTC"}
+	expired := &Cert{DomainName: "expired.example.com", notAfter: time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	failed := &Cert{DomainName: "failed.example.com", Error: "connection refused"}
+
+	policy := ExitPolicy{Warning: 14 * 24 * time.Hour}
+	var tests = []struct {
+		certs Certs
+		want  int
+	}{
+		{Certs{ok}, ExitOK},
+		{Certs{ok, expiring}, ExitWarning},
+		{Certs{expiring, expired}, ExitCritical},
+		{Certs{ok, failed}, ExitCritical},
+		{Certs{}, ExitOK},
+	}
+	for i, test := range tests {
+		if got := test.certs.ExitCode(policy); got != test.want {
+			t.Errorf("#%d ExitCode() = %d, want %d", i, got, test.want)
+		}
+	}
+
+	if got := (Certs{expiring}).ExitCode(ExitPolicy{}); got != ExitOK {
+		t.Errorf("ExitCode() without warning = %d, want %d", got, ExitOK)
+	}
+
+	downgraded := &Cert{DomainName: "failed.example.com", Error: "connection refused", Policy: map[string]string{"unreachable": "warning"}}
+	if got := (Certs{ok, downgraded}).ExitCode(policy); got != ExitWarning {
+		t.Errorf("ExitCode() with downgraded failure = %d, want %d", got, ExitWarning)
+	}
+}
+
+func TestCertsExitCodeWithPolicy(t *testing.T) {
+	now = func() time.Time { return time.Date(2017, time.December, 25, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+	stubCert()
+
+	policy := &Policy{Exceptions: []PolicyException{{Domain: "legacy.example.com", Severities: map[string]string{"expiring": "ignore"}}}}
+	certs, _ := NewCertsWithOptions([]string{"legacy.example.com"}, &Options{Policy: policy})
+	if got := certs.ExitCode(ExitPolicy{Warning: 14 * 24 * time.Hour}); got != ExitOK {
+		t.Errorf("ExitCode() of excepted expiring host = %d, want %d", got, ExitOK)
+	}
+	if ns := NewLadder(14 * 24 * time.Hour).Check(certs); ns != nil {
+		t.Errorf(`unexpected notifications %v for excepted host, want none`, ns)
+	}
+
+	certs, _ = NewCertsWithOptions([]string{"example.com"}, &Options{Policy: policy})
+	if got := certs.ExitCode(ExitPolicy{Warning: 14 * 24 * time.Hour}); got != ExitWarning {
+		t.Errorf("ExitCode() of expiring host = %d, want %d", got, ExitWarning)
+	}
+}
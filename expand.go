@@ -0,0 +1,100 @@
+package cert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxExpansion = 65536
+
+var rangePattern = regexp.MustCompile(`\[(\d+)-(\d+)\]`)
+
+// ExpandTargets expands brace alternatives such as {www,api}.example.com
+// and numeric ranges such as host[01-20].internal:8443 in each target.
+// Ranges keep the zero padding of their start. Brackets which do not hold
+// a range, as in [::1]:443, are left alone.
+func ExpandTargets(s []string) ([]string, error) {
+	var out []string
+	for _, target := range s {
+		expanded, err := expand(target)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+		if len(out) > maxExpansion {
+			return nil, fmt.Errorf("Too many targets after expansion, at most %d.", maxExpansion)
+		}
+	}
+	return out, nil
+}
+
+func expand(target string) ([]string, error) {
+	open := strings.IndexByte(target, '{')
+	m := rangePattern.FindStringSubmatchIndex(target)
+	if open >= 0 && (m == nil || open < m[0]) {
+		depth := 0
+		start := open + 1
+		var alternatives []string
+		for i := open; i < len(target); i++ {
+			switch target[i] {
+			case '{':
+				depth++
+			case ',':
+				if depth == 1 {
+					alternatives = append(alternatives, target[start:i])
+					start = i + 1
+				}
+			case '}':
+				depth--
+				if depth > 0 {
+					continue
+				}
+				alternatives = append(alternatives, target[start:i])
+				var out []string
+				for _, alt := range alternatives {
+					expanded, err := expand(target[:open] + alt + target[i+1:])
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, expanded...)
+					if len(out) > maxExpansion {
+						return nil, fmt.Errorf("Too many targets after expansion, at most %d.", maxExpansion)
+					}
+				}
+				return out, nil
+			}
+		}
+		return nil, fmt.Errorf("Unbalanced brace in %q.", target)
+	}
+
+	if m == nil {
+		return []string{target}, nil
+	}
+	from, to := target[m[2]:m[3]], target[m[4]:m[5]]
+	first, err1 := strconv.Atoi(from)
+	last, err2 := strconv.Atoi(to)
+	if err1 != nil || err2 != nil || first > last {
+		return nil, fmt.Errorf("Invalid range in %q.", target)
+	}
+	if last-first >= maxExpansion {
+		return nil, fmt.Errorf("Too many targets after expansion, at most %d.", maxExpansion)
+	}
+	width := 0
+	if len(from) > 1 && from[0] == '0' {
+		width = len(from)
+	}
+	var out []string
+	for n := first; n <= last; n++ {
+		expanded, err := expand(target[:m[0]] + fmt.Sprintf("%0*d", width, n) + target[m[1]:])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, expanded...)
+		if len(out) > maxExpansion {
+			return nil, fmt.Errorf("Too many targets after expansion, at most %d.", maxExpansion)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package cert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTargets(t *testing.T) {
+	var tests = []struct {
+		input []string
+		want  []string
+	}{
+		{[]string{"example.com"}, []string{"example.com"}},
+		{[]string{"{www,api,mail}.example.com"}, []string{"www.example.com", "api.example.com", "mail.example.com"}},
+		{[]string{"host[08-11].internal:8443"}, []string{"host08.internal:8443", "host09.internal:8443", "host10.internal:8443", "host11.internal:8443"}},
+		{[]string{"web[1-2].{a,b}.example.com"}, []string{"web1.a.example.com", "web1.b.example.com", "web2.a.example.com", "web2.b.example.com"}},
+		{[]string{"{www,{eu,us}.api}.example.com"}, []string{"www.example.com", "eu.api.example.com", "us.api.example.com"}},
+		{[]string{"[::1]:443", "example.org"}, []string{"[::1]:443", "example.org"}},
+	}
+	for _, test := range tests {
+		got, err := ExpandTargets(test.input)
+		if err != nil {
+			t.Errorf("ExpandTargets(%q) unexpected err %s", test.input, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ExpandTargets(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestExpandTargetsError(t *testing.T) {
+	for _, input := range []string{"{www,api.example.com", "host[5-1].example.com", "host[0-99999999].example.com"} {
+		if _, err := ExpandTargets([]string{input}); err == nil {
+			t.Errorf("ExpandTargets(%q) unexpected nil, want error", input)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WritePEM writes the leaf certificate of each host to <dir>/<host>.pem and
+// the rest of the presented chain to <dir>/<host>.chain.pem, where <host>
+// ends in _<port> unless the port is the default one.
+// Hosts which could not be fetched are skipped. Errors of writing or
+// closing a file are returned.
+func (certs Certs) WritePEM(dir string) error {
+	for _, cert := range certs {
+		if len(cert.chain) == 0 {
+			continue
+		}
+		base := filepath.Join(dir, fileName(hostport(cert)))
+		if err := os.WriteFile(base+".pem", encodePEM(cert.chain[:1]), 0644); err != nil {
+			return err
+		}
+		if len(cert.chain) < 2 {
+			continue
+		}
+		if err := os.WriteFile(base+".chain.pem", encodePEM(cert.chain[1:]), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodePEM(chain []*x509.Certificate) []byte {
+	var b bytes.Buffer
+	for _, c := range chain {
+		pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
+	}
+	return b.Bytes()
+}
+
+func fileName(host string) string {
+	return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(host)
+}
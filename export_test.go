@@ -0,0 +1,48 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCertsWritePEM(t *testing.T) {
+	dir := t.TempDir()
+
+	certs := Certs{
+		{DomainName: "example.com", chain: []*x509.Certificate{{Raw: []byte("leaf")}, {Raw: []byte("intermediate")}}},
+		{DomainName: "example.org", chain: []*x509.Certificate{{Raw: []byte("leaf")}}},
+		{DomainName: "example.net", Error: "connection refused"},
+	}
+	if err := certs.WritePEM(dir); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	var tests = []struct {
+		file string
+		want string
+	}{
+		{"example.com.pem", "leaf"},
+		{"example.com.chain.pem", "intermediate"},
+		{"example.org.pem", "leaf"},
+	}
+	for _, test := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, test.file))
+		if err != nil {
+			t.Errorf(`unexpected err %s, want nil`, err.Error())
+			continue
+		}
+		block, _ := pem.Decode(data)
+		if block == nil || string(block.Bytes) != test.want {
+			t.Errorf(`unexpected content of %s, want %q`, test.file, test.want)
+		}
+	}
+
+	for _, file := range []string{"example.org.chain.pem", "example.net.pem"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); !os.IsNotExist(err) {
+			t.Errorf(`unexpected file %s`, file)
+		}
+	}
+}
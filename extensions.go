@@ -0,0 +1,44 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// Extension is a raw X.509 extension of the leaf certificate.
+type Extension struct {
+	OID      string `json:"oid"`
+	Name     string `json:"name,omitempty"`
+	Critical bool   `json:"critical"`
+	Value    string `json:"value"`
+}
+
+var extensionOIDNames = map[string]string{
+	"2.5.29.14":               "subjectKeyIdentifier",
+	"2.5.29.15":               "keyUsage",
+	"2.5.29.17":               "subjectAltName",
+	"2.5.29.19":               "basicConstraints",
+	"2.5.29.30":               "nameConstraints",
+	"2.5.29.31":               "cRLDistributionPoints",
+	"2.5.29.32":               "certificatePolicies",
+	"2.5.29.35":               "authorityKeyIdentifier",
+	"2.5.29.37":               "extKeyUsage",
+	"1.3.6.1.5.5.7.1.1":       "authorityInfoAccess",
+	"1.3.6.1.5.5.7.1.24":      "tlsFeature",
+	"1.3.6.1.4.1.11129.2.4.2": "signedCertificateTimestampList",
+	"1.3.6.1.4.1.11129.2.4.3": "precertificatePoison",
+}
+
+func extensions(cert *x509.Certificate) []Extension {
+	exts := make([]Extension, len(cert.Extensions))
+	for i, e := range cert.Extensions {
+		oid := e.Id.String()
+		exts[i] = Extension{
+			OID:      oid,
+			Name:     extensionOIDNames[oid],
+			Critical: e.Critical,
+			Value:    hex.EncodeToString(e.Value),
+		}
+	}
+	return exts
+}
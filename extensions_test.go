@@ -0,0 +1,32 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"reflect"
+	"testing"
+)
+
+func TestNewCertWithExtensions(t *testing.T) {
+	stubChain(&x509.Certificate{
+		Extensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 19}, Critical: true, Value: []byte{0x30, 0x00}},
+			{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{0xde, 0xad}},
+		},
+	})
+	defer stubCert()
+
+	if c := NewCert("example.com"); c.Extensions != nil {
+		t.Errorf(`unexpected Cert.Extensions %v, want nil`, c.Extensions)
+	}
+
+	c := NewCertWithOptions("example.com", &Options{Extensions: true})
+	want := []Extension{
+		{OID: "2.5.29.19", Name: "basicConstraints", Critical: true, Value: "3000"},
+		{OID: "1.2.3.4", Value: "dead"},
+	}
+	if !reflect.DeepEqual(c.Extensions, want) {
+		t.Errorf(`unexpected Cert.Extensions %v, want %v`, c.Extensions, want)
+	}
+}
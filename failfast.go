@@ -0,0 +1,81 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FailFast selects the results that abort a scan.
+type FailFast int
+
+const (
+	// FailFastOff scans every target.
+	FailFastOff FailFast = iota
+	// FailFastError aborts on the first failed target.
+	FailFastError
+	// FailFastExpired aborts on the first failed target or expired
+	// certificate.
+	FailFastExpired
+)
+
+// ErrorKindAborted marks targets cancelled because the scan was aborted.
+const ErrorKindAborted = "aborted"
+
+// AbortError is returned by NewCertsWithOptions and NewCertsFromTargets
+// when Options.FailFast aborted the scan. The Certs are returned as well;
+// targets cancelled by the abort have ErrorKind ErrorKindAborted.
+type AbortError struct {
+	// Cert is the result that caused the abort.
+	Cert *Cert
+}
+
+func (e *AbortError) Error() string {
+	if e.Cert.Error != "" {
+		return fmt.Sprintf("Aborted because %s failed: %s", e.Cert.DomainName, e.Cert.Error)
+	}
+	return fmt.Sprintf("Aborted because the certificate of %s has expired.", e.Cert.DomainName)
+}
+
+// trips reports whether c aborts a scan. Failures and expiry that
+// Cert.Policy lowers from severity "error" do not.
+func (f FailFast) trips(c *Cert) bool {
+	failed := c.Error != "" && c.severity(errorRule(c)) == "error"
+	switch f {
+	case FailFastError:
+		return failed
+	case FailFastExpired:
+		return failed || c.Status == StatusExpired && c.severity("expired") == "error"
+	}
+	return false
+}
+
+// scanContext is scan with opts.FailFast applied: the first result that
+// trips it cancels ctx for the remaining and in-flight fetches.
+func scanContext(ctx context.Context, n int, opts *Options, fetch func(ctx context.Context, i int) *Cert) (Certs, error) {
+	if opts.FailFast == FailFastOff {
+		return scan(n, opts, func(i int) *Cert { return fetch(ctx, i) }), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var mu sync.Mutex
+	var abort *AbortError
+	certs := scan(n, opts, func(i int) *Cert {
+		c := fetch(ctx, i)
+		mu.Lock()
+		defer mu.Unlock()
+		if abort != nil && c.Error != "" && ctx.Err() != nil {
+			c.ErrorKind = ErrorKindAborted
+		} else if abort == nil && opts.FailFast.trips(c) {
+			opts.logDebug("scan aborted", "host", c.DomainName)
+			abort = &AbortError{Cert: c}
+			cancel()
+		}
+		return c
+	})
+	if abort != nil {
+		return certs, abort
+	}
+	return certs, nil
+}
@@ -0,0 +1,91 @@
+package cert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFailFast(t *testing.T) {
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		switch host {
+		case "bad.example.com":
+			return nil, errors.New("connection refused")
+		case "expired.example.com":
+			return &serverInfo{chain: stubChainCert(host)}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return &serverInfo{chain: stubChainCert(host)}, nil
+		}
+	}
+	defer stubCert()
+
+	start := time.Now()
+	certs, err := NewCertsWithOptions([]string{"slow1.example.com", "bad.example.com", "slow2.example.com"}, &Options{FailFast: FailFastError})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf(`unexpected elapsed %s, want in-flight scans cancelled`, elapsed)
+	}
+	var abort *AbortError
+	if !errors.As(err, &abort) || abort.Cert.DomainName != "bad.example.com" {
+		t.Fatalf(`unexpected err %v, want AbortError for bad.example.com`, err)
+	}
+	if len(certs) != 3 || certs[0].ErrorKind != ErrorKindAborted || certs[2].ErrorKind != ErrorKindAborted {
+		t.Errorf(`unexpected certs %+v, want slow targets aborted`, certs)
+	}
+	if want := "Aborted because bad.example.com failed: connection refused"; err.Error() != want {
+		t.Errorf(`unexpected err %q, want %q`, err.Error(), want)
+	}
+
+	// stubChainCert has a zero NotAfter, so the certificate has expired.
+	certs, err = NewCertsFromTargets([]Target{{Host: "slow1.example.com"}, {Host: "expired.example.com"}}, &Options{FailFast: FailFastExpired})
+	if !errors.As(err, &abort) || abort.Cert.DomainName != "expired.example.com" {
+		t.Fatalf(`unexpected err %v, want AbortError for expired.example.com`, err)
+	}
+	if want := "Aborted because the certificate of expired.example.com has expired."; err.Error() != want {
+		t.Errorf(`unexpected err %q, want %q`, err.Error(), want)
+	}
+	if certs[0].ErrorKind != ErrorKindAborted {
+		t.Errorf(`unexpected Cert %+v, want aborted`, certs[0])
+	}
+}
+
+func TestFailFastOff(t *testing.T) {
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		if host == "bad.example.com" {
+			return nil, errors.New("connection refused")
+		}
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	certs, err := NewCertsWithOptions([]string{"bad.example.com", "good.example.com"}, nil)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if certs[1].Error != "" {
+		t.Errorf(`unexpected Cert.Error %q, want ""`, certs[1].Error)
+	}
+}
+
+func TestFailFastPolicy(t *testing.T) {
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		if host == "bad.example.com" {
+			return nil, errors.New("connection refused")
+		}
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	policy := &Policy{Exceptions: []PolicyException{{Domain: "bad.example.com", Severities: map[string]string{"unreachable": "warning"}}}}
+	certs, err := NewCertsWithOptions([]string{"bad.example.com", "good.example.com"}, &Options{FailFast: FailFastError, Policy: policy})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if certs[1].ErrorKind == ErrorKindAborted {
+		t.Errorf(`unexpected Cert %+v, want scanned`, certs[1])
+	}
+}
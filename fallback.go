@@ -0,0 +1,187 @@
+package cert
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Results of Options.CheckFallbackSCSV in Cert.DowngradeProtection.
+const (
+	// DowngradeProtected means the server refused a handshake at a lower
+	// version carrying TLS_FALLBACK_SCSV with an inappropriate_fallback
+	// alert, as RFC 7507 requires.
+	DowngradeProtected = "protected"
+	// DowngradeUnprotected means the server accepted it.
+	DowngradeUnprotected = "unprotected"
+	// DowngradeNotApplicable means the server supports no lower version,
+	// so there is nothing to downgrade to.
+	DowngradeNotApplicable = "not-applicable"
+	// DowngradeUnknown means the test failed, e.g. with another alert.
+	DowngradeUnknown = "unknown"
+)
+
+// tlsFallbackSCSV is the signalling cipher suite of RFC 7507.
+const tlsFallbackSCSV = 0x5600
+
+// TLS alert descriptions of the fallback test.
+const (
+	alertProtocolVersion       = 70
+	alertInappropriateFallback = 86
+)
+
+// fallbackCipherSuites are offered in the fallback ClientHello, so that
+// the server finds a suite of its own at any version from TLS 1.0 to 1.2.
+var fallbackCipherSuites = []uint16{
+	0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9, 0xcca8, // ECDHE AEAD
+	0xc009, 0xc013, 0xc00a, 0xc014, // ECDHE CBC
+	0x009c, 0x009d, 0x002f, 0x0035, 0x000a, // RSA
+	tlsFallbackSCSV,
+}
+
+// checkFallbackSCSV connects to ip, or host through the proxy if ip is
+// empty, again and offers the version below negotiated with
+// TLS_FALLBACK_SCSV. It returns one of the Downgrade results.
+func checkFallbackSCSV(ctx context.Context, host, ip, port, startTLS string, negotiated uint16, opts *Options) string {
+	if negotiated <= tls.VersionTLS10 {
+		return DowngradeNotApplicable
+	}
+	version := negotiated - 1
+	if negotiated == tls.VersionTLS13 {
+		version = tls.VersionTLS12
+	}
+
+	_, span := startSpan(ctx, opts, "cert.fallback", "host", host, "port", port)
+	result, err := fallbackHandshake(ctx, host, ip, port, startTLS, version, opts)
+	span.End(err)
+	if err != nil {
+		opts.logDebug("fallback test failed", "host", host, "port", port, "err", err)
+		return DowngradeUnknown
+	}
+	return result
+}
+
+func fallbackHandshake(ctx context.Context, host, ip, port, startTLS string, version uint16, opts *Options) (string, error) {
+	var conn net.Conn
+	var err error
+	if ip != "" {
+		conn, err = dialAny(ctx, []net.IPAddr{{IP: net.ParseIP(ip)}}, port, opts)
+	} else {
+		var proxy *url.URL
+		if opts.Proxy != nil {
+			proxy, err = opts.Proxy(host)
+		}
+		if err == nil && proxy == nil {
+			err = fmt.Errorf("No address of %s.", host)
+		}
+		if err == nil {
+			conn, err = dialProxy(ctx, proxy, net.JoinHostPort(host, port), opts)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if startTLS != "" {
+		if err := negotiateStartTLS(ctx, conn, startTLS, opts); err != nil {
+			return "", err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	serverName := ""
+	if net.ParseIP(host) == nil {
+		serverName = host
+	}
+	if _, err := conn.Write(clientHello(version, serverName)); err != nil {
+		return "", err
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	body := make([]byte, 2)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return "", err
+	}
+	switch header[0] {
+	case 21: // alert
+		switch body[1] {
+		case alertInappropriateFallback:
+			return DowngradeProtected, nil
+		case alertProtocolVersion:
+			return DowngradeNotApplicable, nil
+		}
+		return "", fmt.Errorf("alert %d", body[1])
+	case 22: // handshake
+		if body[0] == 2 { // ServerHello
+			return DowngradeUnprotected, nil
+		}
+	}
+	return "", fmt.Errorf("unexpected record type %d", header[0])
+}
+
+// clientHello returns a TLS record holding a ClientHello of version, with
+// fallbackCipherSuites and the extensions servers commonly require.
+func clientHello(version uint16, serverName string) []byte {
+	u16 := func(b []byte, v int) []byte { return binary.BigEndian.AppendUint16(b, uint16(v)) }
+	extension := func(b []byte, typ int, data []byte) []byte {
+		b = u16(b, typ)
+		b = u16(b, len(data))
+		return append(b, data...)
+	}
+
+	var exts []byte
+	if serverName != "" {
+		var list []byte
+		list = append(list, 0) // host_name
+		list = u16(list, len(serverName))
+		list = append(list, serverName...)
+		exts = extension(exts, 0x0000, append(u16(nil, len(list)), list...))
+	}
+	// supported_groups x25519, secp256r1, secp384r1
+	exts = extension(exts, 0x000a, []byte{0, 6, 0x00, 0x1d, 0x00, 0x17, 0x00, 0x18})
+	// ec_point_formats uncompressed
+	exts = extension(exts, 0x000b, []byte{1, 0})
+	if version >= tls.VersionTLS12 {
+		algs := []uint16{0x0403, 0x0503, 0x0603, 0x0804, 0x0805, 0x0806, 0x0401, 0x0501, 0x0601, 0x0201, 0x0203}
+		data := u16(nil, 2*len(algs))
+		for _, alg := range algs {
+			data = u16(data, int(alg))
+		}
+		exts = extension(exts, 0x000d, data)
+	}
+	// renegotiation_info, empty
+	exts = extension(exts, 0xff01, []byte{0})
+
+	var hello []byte
+	hello = u16(hello, int(version))
+	random := make([]byte, 32)
+	rand.Read(random)
+	hello = append(hello, random...)
+	hello = append(hello, 0) // session_id
+	hello = u16(hello, 2*len(fallbackCipherSuites))
+	for _, suite := range fallbackCipherSuites {
+		hello = u16(hello, int(suite))
+	}
+	hello = append(hello, 1, 0) // compression_methods null
+	hello = u16(hello, len(exts))
+	hello = append(hello, exts...)
+
+	msg := []byte{1, byte(len(hello) >> 16), byte(len(hello) >> 8), byte(len(hello))}
+	msg = append(msg, hello...)
+	record := []byte{22, 3, 1}
+	record = u16(record, len(msg))
+	return append(record, msg...)
+}
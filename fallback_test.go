@@ -0,0 +1,60 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestCheckFallbackSCSV(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	leaf := newTestCert(t, &x509.Certificate{DNSNames: []string{"localhost"}}, nil)
+	pair := tls.Certificate{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}
+	tests := []struct {
+		config *tls.Config
+		want   string
+	}{
+		{&tls.Config{Certificates: []tls.Certificate{pair}}, DowngradeProtected},
+		{&tls.Config{Certificates: []tls.Certificate{pair}, MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12}, DowngradeNotApplicable},
+	}
+	for _, test := range tests {
+		host, port := startTLSServer(t, test.config)
+		c := NewCertWithOptions(net.JoinHostPort(host, port), &Options{InsecureSkipVerify: true, CheckFallbackSCSV: true})
+		if c.DowngradeProtection != test.want {
+			t.Errorf(`unexpected DowngradeProtection %q, want %q`, c.DowngradeProtection, test.want)
+		}
+	}
+}
+
+func TestCheckFallbackSCSVUnprotected(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.ReadFull(conn, make([]byte, 5))
+		// The start of a ServerHello.
+		conn.Write([]byte{22, 3, 2, 0, 4, 2, 0, 0, 0})
+	}()
+
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	got := checkFallbackSCSV(context.Background(), "example.com", "127.0.0.1", port, "", tls.VersionTLS12, &Options{})
+	if got != DowngradeUnprotected {
+		t.Errorf(`unexpected result %q, want %q`, got, DowngradeUnprotected)
+	}
+	c := &Cert{DowngradeProtection: got}
+	if fs := findings(c); len(fs) != 1 || fs[0].Rule != "no-downgrade-protection" {
+		t.Errorf(`unexpected findings %+v, want no-downgrade-protection`, fs)
+	}
+}
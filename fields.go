@@ -0,0 +1,86 @@
+package cert
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// truncated are the fields shortened to Options.MaxWidth.
+var truncated = map[string]bool{"Issuer": true, "CommonName": true, "Error": true}
+
+// Selection is a view of Certs which renders only the chosen fields.
+type Selection struct {
+	certs  Certs
+	fields []string
+}
+
+// WithFields returns a view of certs whose String and Markdown output
+// contain only the named Cert fields, in the given order.
+// It panics if a name is not an exported field of Cert.
+func (certs Certs) WithFields(fields ...string) Selection {
+	if err := ValidateFields(fields...); err != nil {
+		panic(err)
+	}
+	return Selection{certs: certs, fields: fields}
+}
+
+// ValidateFields reports an error if a name is not an exported field of Cert.
+func ValidateFields(fields ...string) error {
+	t := reflect.TypeOf(Cert{})
+	for _, name := range fields {
+		if f, ok := t.FieldByName(name); !ok || f.PkgPath != "" {
+			return fmt.Errorf("Unknown field %q.", name)
+		}
+	}
+	return nil
+}
+
+func (s Selection) String() string {
+	width := 0
+	for _, name := range s.fields {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	var t strings.Builder
+	t.WriteString("{{range .}}")
+	for _, name := range s.fields {
+		switch {
+		case name == "SANs":
+			fmt.Fprintf(&t, "%-*s{{trunc . (print (sans .))}}\n", width+2, name+":")
+		case truncated[name]:
+			fmt.Fprintf(&t, "%-*s{{trunc . .%s}}\n", width+2, name+":", name)
+		default:
+			fmt.Fprintf(&t, "%-*s{{.%s}}\n", width+2, name+":", name)
+		}
+	}
+	t.WriteString("\n{{end}}\n")
+	return execute("fields", t.String(), s.certs)
+}
+
+func (s Selection) Markdown() string {
+	header := make([]string, len(s.fields))
+	rule := make([]string, len(s.fields))
+	row := make([]string, len(s.fields))
+	for i, name := range s.fields {
+		header[i] = name
+		rule[i] = "---"
+		row[i] = "{{." + name + "}}"
+		if truncated[name] {
+			row[i] = "{{trunc . ." + name + "}}"
+		}
+		switch name {
+		case "CommonName":
+			header[i] = "CN"
+		case "SANs":
+			row[i] = "{{range sans .}}{{trunc $c .}}<br/>{{end}}"
+		}
+	}
+
+	t := strings.Join(header, " | ") + "\n" +
+		strings.Join(rule, " | ") + "\n" +
+		"{{range $c := .}}" + strings.Join(row, " | ") + "\n{{end}}\n"
+	return execute("fields", t, s.certs.escapeStar())
+}
@@ -0,0 +1,63 @@
+package cert
+
+import (
+	"testing"
+)
+
+func TestCertsWithFieldsAsString(t *testing.T) {
+	certs := Certs{
+		{DomainName: "example.com", Issuer: "CA for test", NotAfter: "2018-01-01 00:00:00 +0000 UTC"},
+		{DomainName: "example.org", Error: "connection refused"},
+	}
+
+	expected := `DomainName: example.com
+NotAfter:   2018-01-01 00:00:00 +0000 UTC
+Issuer:     CA for test
+
+DomainName: example.org
+NotAfter:   
+Issuer:     
+
+
+`
+	got := certs.WithFields("DomainName", "NotAfter", "Issuer").String()
+	if got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+}
+
+func TestCertsWithFieldsAsMarkdown(t *testing.T) {
+	certs := Certs{
+		{DomainName: "example.com", CommonName: "example.com", SANs: []string{"example.com", "*.example.com"}},
+	}
+
+	expected := `DomainName | CN | SANs
+--- | --- | ---
+example.com | example.com | example.com<br/>\*.example.com<br/>
+
+`
+	got := certs.WithFields("DomainName", "CommonName", "SANs").Markdown()
+	if got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+}
+
+func TestCertsWithUnknownField(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`unexpected nil, want panic`)
+		}
+	}()
+	Certs{}.WithFields("DomainName", "chain")
+}
+
+func TestValidateFields(t *testing.T) {
+	if err := ValidateFields("DomainName", "NotAfter"); err != nil {
+		t.Errorf(`unexpected err %s, want nil`, err.Error())
+	}
+	if err := ValidateFields("DomainName", "Expiry"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	} else if err.Error() != `Unknown field "Expiry".` {
+		t.Errorf(`unexpected err message %q, want %q`, err.Error(), `Unknown field "Expiry".`)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/genkiroid/cert
+
+go 1.26.0
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	go.yaml.in/yaml/v3 v3.0.5
+	golang.org/x/net v0.59.0
+)
+
+require golang.org/x/text v0.42.0 // indirect
@@ -0,0 +1,64 @@
+package cert
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+var issuersTempl = `{{range .}}{{.Issuer}} ({{.Count}})
+{{range .Domains}}  {{.}}
+{{end}}
+{{end}}`
+
+// IssuerGroup lists the domains whose certificates share an issuer.
+type IssuerGroup struct {
+	Issuer  string   `json:"issuer"`
+	Count   int      `json:"count"`
+	Domains []string `json:"domains"`
+}
+
+// IssuerGroups is a list of IssuerGroup.
+type IssuerGroups []IssuerGroup
+
+// ByIssuer groups certs by Issuer, largest group first. Failed certs,
+// which have no issuer, are left out.
+func (certs Certs) ByIssuer() IssuerGroups {
+	index := map[string]int{}
+	var groups IssuerGroups
+	for _, c := range certs {
+		if c.Error != "" {
+			continue
+		}
+		i, ok := index[c.Issuer]
+		if !ok {
+			i = len(groups)
+			index[c.Issuer] = i
+			groups = append(groups, IssuerGroup{Issuer: c.Issuer})
+		}
+		groups[i].Count++
+		groups[i].Domains = append(groups[i].Domains, c.DomainName)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Issuer < groups[j].Issuer
+	})
+	return groups
+}
+
+func (groups IssuerGroups) String() string {
+	return execute("issuers", issuersTempl, groups)
+}
+
+// JSON returns the groups as JSON.
+func (groups IssuerGroups) JSON() []byte {
+	if groups == nil {
+		groups = IssuerGroups{}
+	}
+	data, err := json.Marshal(groups)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,48 @@
+package cert
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCertsByIssuer(t *testing.T) {
+	certs := Certs{
+		{DomainName: "a.example.com", Issuer: "Old CA"},
+		{DomainName: "b.example.com", Issuer: "New CA"},
+		{DomainName: "c.example.com", Issuer: "Old CA"},
+		{DomainName: "d.example.com", Issuer: "Other CA"},
+		{DomainName: "e.example.com", Error: "connection refused"},
+	}
+	want := IssuerGroups{
+		{Issuer: "Old CA", Count: 2, Domains: []string{"a.example.com", "c.example.com"}},
+		{Issuer: "New CA", Count: 1, Domains: []string{"b.example.com"}},
+		{Issuer: "Other CA", Count: 1, Domains: []string{"d.example.com"}},
+	}
+	got := certs.ByIssuer()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected groups %+v, want %+v`, got, want)
+	}
+
+	expected := `Old CA (2)
+  a.example.com
+  c.example.com
+
+New CA (1)
+  b.example.com
+
+Other CA (1)
+  d.example.com
+
+`
+	if got.String() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got.String(), expected)
+	}
+
+	expectedJSON := `[{"issuer":"Old CA","count":2,"domains":["a.example.com","c.example.com"]},{"issuer":"New CA","count":1,"domains":["b.example.com"]},{"issuer":"Other CA","count":1,"domains":["d.example.com"]}]`
+	if string(got.JSON()) != expectedJSON {
+		t.Errorf(`unexpected return value %q, want %q`, got.JSON(), expectedJSON)
+	}
+	if string(Certs{}.ByIssuer().JSON()) != `[]` {
+		t.Errorf(`unexpected return value %q, want %q`, Certs{}.ByIssuer().JSON(), `[]`)
+	}
+}
@@ -0,0 +1,121 @@
+package cert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bucket sizes of ExpiryHistogram.
+const (
+	Weekly  = "week"
+	Monthly = "month"
+)
+
+// histogramBarWidth is the length of the longest bar of the text chart.
+const histogramBarWidth = 40
+
+// ExpiryBucket counts the certificates expiring from Start until End.
+type ExpiryBucket struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Count   int       `json:"count"`
+	Domains []string  `json:"domains"`
+}
+
+// ExpiryHistogram counts the certificates expiring per week or month over
+// the next year.
+type ExpiryHistogram struct {
+	Interval string         `json:"interval"`
+	Buckets  []ExpiryBucket `json:"buckets"`
+	// Expired counts certificates that have expired, Later those expiring
+	// after the last bucket and Failed those that could not be fetched.
+	Expired int `json:"expired"`
+	Later   int `json:"later"`
+	Failed  int `json:"failed"`
+}
+
+// ExpiryHistogram returns the expiry distribution of certs in buckets of
+// interval, Weekly or Monthly, starting this week on Monday or this month.
+func (certs Certs) ExpiryHistogram(interval string) (*ExpiryHistogram, error) {
+	t := now()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	var next func(time.Time) time.Time
+	var n int
+	switch interval {
+	case Weekly:
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+		n = 53
+	case Monthly:
+		start = start.AddDate(0, 0, 1-start.Day())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+		n = 13
+	default:
+		return nil, fmt.Errorf("Unknown interval %q.", interval)
+	}
+
+	h := &ExpiryHistogram{Interval: interval, Buckets: make([]ExpiryBucket, n)}
+	for i := range h.Buckets {
+		h.Buckets[i].Start = start
+		start = next(start)
+		h.Buckets[i].End = start
+		h.Buckets[i].Domains = []string{}
+	}
+	for _, c := range certs {
+		notAfter, ok := c.expiry()
+		switch {
+		case c.Error != "" || !ok:
+			h.Failed++
+			continue
+		case now().After(notAfter):
+			h.Expired++
+			continue
+		case !notAfter.Before(start):
+			h.Later++
+			continue
+		}
+		for i := range h.Buckets {
+			if notAfter.Before(h.Buckets[i].End) {
+				h.Buckets[i].Count++
+				h.Buckets[i].Domains = append(h.Buckets[i].Domains, c.DomainName)
+				break
+			}
+		}
+	}
+	return h, nil
+}
+
+// String renders h as a text chart with one bar per bucket.
+func (h *ExpiryHistogram) String() string {
+	max := 0
+	for _, b := range h.Buckets {
+		if b.Count > max {
+			max = b.Count
+		}
+	}
+	layout := "2006-01-02"
+	if h.Interval == Monthly {
+		layout = "2006-01"
+	}
+	var s strings.Builder
+	for _, b := range h.Buckets {
+		bar := 0
+		if max > 0 {
+			bar = (b.Count*histogramBarWidth + max - 1) / max
+		}
+		fmt.Fprintf(&s, "%-10s %s %d\n", b.Start.Format(layout), strings.Repeat("#", bar), b.Count)
+	}
+	fmt.Fprintf(&s, "Expired: %d, later: %d, failed: %d\n", h.Expired, h.Later, h.Failed)
+	return s.String()
+}
+
+// JSON returns h as JSON.
+func (h *ExpiryHistogram) JSON() []byte {
+	data, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,61 @@
+package cert
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpiryHistogram(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 10, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "a.example.com", NotAfter: "2018-01-20 00:00:00 +0000 UTC"},
+		{DomainName: "b.example.com", NotAfter: "2018-01-31 23:00:00 +0000 UTC"},
+		{DomainName: "c.example.com", NotAfter: "2018-03-01 00:00:00 +0000 UTC"},
+		{DomainName: "expired.example.com", NotAfter: "2018-01-01 00:00:00 +0000 UTC"},
+		{DomainName: "later.example.com", NotAfter: "2020-01-01 00:00:00 +0000 UTC"},
+		{DomainName: "down.example.com", Error: "connection refused"},
+	}
+
+	h, err := certs.ExpiryHistogram(Monthly)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(h.Buckets) != 13 || !h.Buckets[0].Start.Equal(time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf(`unexpected buckets %+v`, h.Buckets)
+	}
+	if h.Buckets[0].Count != 2 || h.Buckets[1].Count != 0 || h.Buckets[2].Count != 1 || h.Buckets[2].Domains[0] != "c.example.com" {
+		t.Errorf(`unexpected buckets %+v`, h.Buckets[:3])
+	}
+	if h.Expired != 1 || h.Later != 1 || h.Failed != 1 {
+		t.Errorf(`unexpected expired %d, later %d, failed %d, want 1 each`, h.Expired, h.Later, h.Failed)
+	}
+	got := h.String()
+	for _, want := range []string{
+		"2018-01    " + strings.Repeat("#", 40) + " 2\n",
+		"2018-02     0\n",
+		"2018-03    " + strings.Repeat("#", 20) + " 1\n",
+		"Expired: 1, later: 1, failed: 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf(`unexpected return value %q, want %q`, got, want)
+		}
+	}
+	if !strings.Contains(string(h.JSON()), `"interval":"month","buckets":[{"start":"2018-01-01T00:00:00Z"`) {
+		t.Errorf(`unexpected JSON %s`, h.JSON())
+	}
+
+	h, _ = certs.ExpiryHistogram(Weekly)
+	if len(h.Buckets) != 53 || !h.Buckets[0].Start.Equal(time.Date(2018, time.January, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf(`unexpected first bucket %+v, want the week of Monday 2018-01-08`, h.Buckets[0])
+	}
+	if h.Buckets[1].Count != 1 || h.Buckets[3].Count != 1 || h.Buckets[7].Count != 1 {
+		t.Errorf(`unexpected buckets %+v`, h.Buckets[:8])
+	}
+
+	if _, err := certs.ExpiryHistogram("day"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
@@ -0,0 +1,75 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HSTS is the Strict-Transport-Security policy a server sends.
+type HSTS struct {
+	Present           bool   `json:"present"`
+	MaxAge            int64  `json:"maxAge,omitempty"`
+	IncludeSubDomains bool   `json:"includeSubDomains,omitempty"`
+	Preload           bool   `json:"preload,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// probeHSTS sends a HEAD request for / of serverName and port over an
+// established connection and parses the Strict-Transport-Security header
+// of the response. It gives up after timeout, unless zero, or when ctx is
+// done.
+func probeHSTS(ctx context.Context, conn net.Conn, serverName, port string, timeout time.Duration) *HSTS {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	host := serverName
+	if port != defaultPort {
+		host = net.JoinHostPort(serverName, port)
+	}
+	u := &url.URL{Scheme: "https", Host: host, Path: "/"}
+	req, err := http.NewRequestWithContext(ctx, "HEAD", u.String(), nil)
+	if err != nil {
+		return &HSTS{Error: err.Error()}
+	}
+	req.Header.Set("User-Agent", "cert")
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		return &HSTS{Error: err.Error()}
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return &HSTS{Error: err.Error()}
+	}
+	resp.Body.Close()
+	return parseHSTS(resp.Header.Get("Strict-Transport-Security"))
+}
+
+func parseHSTS(header string) *HSTS {
+	h := &HSTS{Present: header != ""}
+	for _, directive := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "max-age":
+			h.MaxAge, _ = strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		case "includesubdomains":
+			h.IncludeSubDomains = true
+		case "preload":
+			h.Preload = true
+		}
+	}
+	return h
+}
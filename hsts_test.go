@@ -0,0 +1,51 @@
+package cert
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHSTS(t *testing.T) {
+	var tests = []struct {
+		header string
+		want   HSTS
+	}{
+		{"", HSTS{}},
+		{"max-age=31536000", HSTS{Present: true, MaxAge: 31536000}},
+		{`max-age="63072000"; includeSubDomains; preload`, HSTS{Present: true, MaxAge: 63072000, IncludeSubDomains: true, Preload: true}},
+	}
+	for _, test := range tests {
+		if got := parseHSTS(test.header); !reflect.DeepEqual(*got, test.want) {
+			t.Errorf("parseHSTS(%q) = %+v, want %+v", test.header, *got, test.want)
+		}
+	}
+}
+
+func TestNewCertWithHSTS(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	var host string
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Header().Set("Strict-Transport-Security", "max-age=300; includeSubDomains")
+	}))
+	defer s.Close()
+
+	c := NewCertWithOptions(s.Listener.Addr().String(), &Options{InsecureSkipVerify: true, HSTS: true, ServerName: "www.example.com", Timeout: 5 * time.Second})
+	want := &HSTS{Present: true, MaxAge: 300, IncludeSubDomains: true}
+	if !reflect.DeepEqual(c.HSTS, want) {
+		t.Errorf(`unexpected Cert.HSTS %+v, want %+v`, c.HSTS, want)
+	}
+	if _, port, _ := net.SplitHostPort(s.Listener.Addr().String()); host != "www.example.com:"+port {
+		t.Errorf(`unexpected Host %q, want %q`, host, "www.example.com:"+port)
+	}
+
+	if c := NewCertWithOptions(s.Listener.Addr().String(), &Options{InsecureSkipVerify: true}); c.HSTS != nil {
+		t.Errorf(`unexpected Cert.HSTS %+v, want nil`, c.HSTS)
+	}
+}
@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTPSink posts results as JSON to an HTTP endpoint such as a Splunk HTTP
+// Event Collector or an Elasticsearch ingest pipeline. Results are sent in
+// batches, one at a time, so a slow endpoint slows the sender instead of
+// piling up requests.
+type HTTPSink struct {
+	URL string `json:"url"`
+	// Token is sent in TokenHeader, which defaults to "Authorization",
+	// e.g. "Splunk <token>" for HEC.
+	Token       string `json:"token,omitempty"`
+	TokenHeader string `json:"tokenHeader,omitempty"`
+	// HEC sends each Cert as a {"event": ...} object as the Splunk HTTP
+	// Event Collector expects, instead of sending a JSON array.
+	HEC bool `json:"hec,omitempty"`
+	// BatchSize defaults to 100 results per request.
+	BatchSize int `json:"batchSize,omitempty"`
+	// Retries is the number of retries after a failed request. Network
+	// errors, 429 and 5xx responses are retried after Backoff, doubling
+	// each time, or after the server's Retry-After.
+	Retries int      `json:"retries,omitempty"`
+	Backoff Duration `json:"backoff,omitempty"`
+	// Client defaults to a client timing out after 30 seconds.
+	Client *http.Client `json:"-"`
+}
+
+var defaultHTTPSinkClient = &http.Client{Timeout: 30 * time.Second}
+
+// Send posts certs in batches.
+func (s *HTTPSink) Send(certs Certs) error {
+	return s.SendContext(context.Background(), certs)
+}
+
+// SendContext is Send, giving up on the request or the wait before a retry
+// when ctx is done.
+func (s *HTTPSink) SendContext(ctx context.Context, certs Certs) error {
+	size := s.BatchSize
+	if size <= 0 {
+		size = 100
+	}
+	for start := 0; start < len(certs); start += size {
+		end := start + size
+		if end > len(certs) {
+			end = len(certs)
+		}
+		if err := s.post(ctx, s.body(certs[start:end])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *HTTPSink) body(certs Certs) []byte {
+	if !s.HEC {
+		return certs.JSON()
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, c := range certs {
+		if err := enc.Encode(struct {
+			Event      *Cert  `json:"event"`
+			SourceType string `json:"sourcetype"`
+		}{c, "cert"}); err != nil {
+			panic(err)
+		}
+	}
+	return b.Bytes()
+}
+
+func (s *HTTPSink) post(ctx context.Context, body []byte) error {
+	client := s.Client
+	if client == nil {
+		client = defaultHTTPSinkClient
+	}
+	header := s.TokenHeader
+	if header == "" {
+		header = "Authorization"
+	}
+	backoff := time.Duration(s.Backoff)
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if s.Token != "" {
+			req.Header.Set(header, s.Token)
+		}
+
+		wait := backoff << uint(attempt)
+		resp, err := client.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("Unexpected response %s from %s.", resp.Status, s.URL)
+			if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+				return err
+			}
+			if secs, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && secs >= 0 {
+				wait = time.Duration(secs) * time.Second
+			}
+		}
+		if attempt >= s.Retries {
+			return err
+		}
+		logDebug("retrying http sink", "url", s.URL, "attempt", attempt+1, "wait", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
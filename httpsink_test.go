@@ -0,0 +1,102 @@
+package cert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSinkSend(t *testing.T) {
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Splunk token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+	}))
+	defer s.Close()
+
+	certs := Certs{{DomainName: "a.example.com"}, {DomainName: "b.example.com"}, {DomainName: "c.example.com"}}
+	sink := &HTTPSink{URL: s.URL, Token: "Splunk token", BatchSize: 2}
+	if err := sink.Send(certs); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(bodies) != 2 {
+		t.Fatalf(`unexpected %d requests, want 2`, len(bodies))
+	}
+	var batch []Cert
+	if err := json.Unmarshal([]byte(bodies[0]), &batch); err != nil || len(batch) != 2 {
+		t.Errorf(`unexpected body %q, want JSON array of 2`, bodies[0])
+	}
+
+	bodies = nil
+	sink.HEC = true
+	if err := sink.Send(certs[:1]); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(bodies) != 1 || !strings.HasPrefix(bodies[0], `{"event":{"domainName":"a.example.com"`) || !strings.HasSuffix(bodies[0], `,"sourcetype":"cert"}`+"\n") {
+		t.Errorf(`unexpected body %q, want HEC event`, bodies)
+	}
+
+	sink.Token = "wrong"
+	if err := sink.Send(certs[:1]); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestHTTPSinkRetry(t *testing.T) {
+	var calls int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer s.Close()
+
+	sink := &HTTPSink{URL: s.URL, Retries: 2, Backoff: Duration(time.Millisecond)}
+	if err := sink.Send(Certs{{DomainName: "example.com"}}); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf(`unexpected %d requests, want 3`, n)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	sink.Retries = 1
+	if err := sink.Send(Certs{{DomainName: "example.com"}}); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf(`unexpected %d requests, want 2`, n)
+	}
+}
+
+func TestHTTPSinkRetryCancelled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	sink := &HTTPSink{URL: s.URL, Retries: 3, Backoff: Duration(time.Hour)}
+	start := time.Now()
+	if err := sink.SendContext(ctx, Certs{{DomainName: "example.com"}}); err != context.DeadlineExceeded {
+		t.Errorf(`unexpected err %v, want %v`, err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf(`unexpected elapsed %s, want the wait cancelled`, elapsed)
+	}
+}
@@ -0,0 +1,67 @@
+package cert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// ICal returns an iCalendar (RFC 5545) calendar with an all-day event on
+// the expiry date of each certificate, and an alarm reminder before it.
+// A zero reminder adds no alarms. Failed certificates are left out.
+func (certs Certs) ICal(reminder time.Duration) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(foldICal(fmt.Sprintf(format, args...)))
+		b.WriteString("\r\n")
+	}
+	stamp := now().UTC().Format("20060102T150405Z")
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//genkiroid//cert//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, c := range certs {
+		notAfter, ok := c.expiry()
+		if c.Error != "" || !ok {
+			continue
+		}
+		day := notAfter.Format("20060102")
+		line("BEGIN:VEVENT")
+		line("UID:%s-%s@cert", icalEscaper.Replace(hostport(c)), day)
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART;VALUE=DATE:%s", day)
+		line("DTEND;VALUE=DATE:%s", notAfter.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:Certificate of %s expires", icalEscaper.Replace(hostport(c)))
+		line("DESCRIPTION:%s", icalEscaper.Replace(fmt.Sprintf("Issuer: %s\nCommonName: %s\nNotAfter: %s", c.Issuer, c.CommonName, c.NotAfter)))
+		if reminder > 0 {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:Certificate of %s expires on %s", icalEscaper.Replace(hostport(c)), notAfter.Format("2006-01-02"))
+			line("TRIGGER:-PT%dM", int(reminder.Minutes()))
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// foldICal folds s into lines of at most 75 octets, continued by a space,
+// without splitting UTF-8 sequences.
+func foldICal(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
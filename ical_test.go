@@ -0,0 +1,54 @@
+package cert
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertsICal(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "example.com", Issuer: "Example CA, Inc.", CommonName: "example.com", NotAfter: "2018-03-01 09:00:00 +0000 UTC"},
+		{DomainName: "down.example.com", Error: "connection refused"},
+	}
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//genkiroid//cert//EN\r\n" +
+		"CALSCALE:GREGORIAN\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:example.com-20180301@cert\r\n" +
+		"DTSTAMP:20180101T000000Z\r\n" +
+		"DTSTART;VALUE=DATE:20180301\r\n" +
+		"DTEND;VALUE=DATE:20180302\r\n" +
+		"SUMMARY:Certificate of example.com expires\r\n" +
+		"DESCRIPTION:Issuer: Example CA\\, Inc.\\nCommonName: example.com\\nNotAfter: 2\r\n" +
+		" 018-03-01 09:00:00 +0000 UTC\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"DESCRIPTION:Certificate of example.com expires on 2018-03-01\r\n" +
+		"TRIGGER:-PT20160M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if got := certs.ICal(14 * 24 * time.Hour); got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+	if got := certs.ICal(0); strings.Contains(got, "VALARM") {
+		t.Errorf(`unexpected return value %q, want no alarm`, got)
+	}
+}
+
+func TestFoldICal(t *testing.T) {
+	s := "DESCRIPTION:" + strings.Repeat("ü", 40)
+	for _, line := range strings.Split(foldICal(s), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf(`unexpected line of %d octets, want at most 75`, len(line))
+		}
+	}
+	if got := strings.Replace(foldICal(s), "\r\n ", "", -1); got != s {
+		t.Errorf(`unexpected unfolded %q, want %q`, got, s)
+	}
+}
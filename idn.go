@@ -0,0 +1,50 @@
+package cert
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// toASCII converts the labels of an internationalized domain name to
+// xn-- A-labels as IDNA lookups do, mapping and normalizing them first.
+// Names that are plain ASCII are returned unchanged.
+func toASCII(host string) (string, error) {
+	if isASCII(host) {
+		return host, nil
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("Invalid domain name %q.", host)
+	}
+	return ascii, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// toUnicode converts the xn-- labels of host to Unicode. Labels that do not
+// decode are kept as they are.
+func toUnicode(host string) string {
+	if !strings.Contains(strings.ToLower(host), "xn--") {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), "xn--") {
+			continue
+		}
+		if decoded, err := idna.Lookup.ToUnicode(strings.ToLower(label)); err == nil {
+			labels[i] = decoded
+		}
+	}
+	return strings.Join(labels, ".")
+}
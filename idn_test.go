@@ -0,0 +1,84 @@
+package cert
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"example.com", "example.com"},
+		{"münchen.example", "xn--mnchen-3ya.example"},
+		{"MÜNCHEN.example", "xn--mnchen-3ya.example"},
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"日本語.jp", "xn--wgv71a119e.jp"},
+		{"例え。テスト", "xn--r8jz45g.xn--zckzah"},
+		{"例え．テスト｡jp", "xn--r8jz45g.xn--zckzah.jp"},
+		{"mu\u0308nchen.example", "xn--mnchen-3ya.example"},
+		{"Bücher.DE", "xn--bcher-kva.de"},
+	}
+	for _, test := range tests {
+		got, err := toASCII(test.in)
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if got != test.want {
+			t.Errorf(`unexpected A-labels %q for %q, want %q`, got, test.in, test.want)
+		}
+	}
+
+	for _, host := range []string{"bad\xff\xfe.example", "aא.example", "ab\u200d.example"} {
+		if _, err := toASCII(host); err == nil {
+			t.Errorf(`unexpected nil for %q, want error`, host)
+		}
+	}
+}
+
+func TestNewCertIDN(t *testing.T) {
+	var dialed string
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		dialed = host
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	c := NewCert("münchen.example")
+	if dialed != "xn--mnchen-3ya.example" {
+		t.Errorf(`unexpected dialed host %q, want %q`, dialed, "xn--mnchen-3ya.example")
+	}
+	if c.DomainName != "xn--mnchen-3ya.example" || c.UnicodeName != "münchen.example" {
+		t.Errorf(`unexpected names %q and %q, want both forms`, c.DomainName, c.UnicodeName)
+	}
+
+	if c := NewCert("example.com"); c.UnicodeName != "" {
+		t.Errorf(`unexpected unicode name %q, want empty`, c.UnicodeName)
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"example.com", "example.com"},
+		{"xn--mnchen-3ya.example", "münchen.example"},
+		{"XN--BCHER-KVA.de", "bücher.de"},
+		{"xn--r8jz45g.xn--zckzah", "例え.テスト"},
+		{"xn--wgv71a119e.jp", "日本語.jp"},
+		{"xn--zz.example", "xn--zz.example"},
+		{"xn--99999999999.example", "xn--99999999999.example"},
+	}
+	for _, test := range tests {
+		if got := toUnicode(test.in); got != test.want {
+			t.Errorf(`unexpected Unicode form %q of %q, want %q`, got, test.in, test.want)
+		}
+		if ascii, _ := toASCII(toUnicode(test.in)); test.want != test.in && ascii != strings.ToLower(test.in) {
+			t.Errorf(`unexpected round trip %q of %q`, ascii, test.in)
+		}
+	}
+}
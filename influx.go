@@ -0,0 +1,37 @@
+package cert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	influxTagEscaper   = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxFieldEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// Influx returns certs in InfluxDB line protocol, one "cert" point per
+// domain tagged with domain, port and issuer. Failed certs have no days_remaining.
+func (certs Certs) Influx() string {
+	var b strings.Builder
+	ts := now().UnixNano()
+	for _, c := range certs {
+		b.WriteString("cert,domain=")
+		b.WriteString(influxTagEscaper.Replace(c.DomainName))
+		if c.Port != "" {
+			b.WriteString(",port=")
+			b.WriteString(c.Port)
+		}
+		if c.Issuer != "" {
+			b.WriteString(",issuer=")
+			b.WriteString(influxTagEscaper.Replace(c.Issuer))
+		}
+		b.WriteByte(' ')
+		if notAfter, ok := c.expiry(); ok && c.Error == "" {
+			fmt.Fprintf(&b, "days_remaining=%di,", int64(notAfter.Sub(now())/(24*time.Hour)))
+		}
+		fmt.Fprintf(&b, "status=\"%s\",error=\"%s\" %d\n", c.Status, influxFieldEscaper.Replace(c.Error), ts)
+	}
+	return b.String()
+}
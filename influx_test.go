@@ -0,0 +1,22 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertsInflux(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "example.com", Issuer: "Example CA, Inc=1", NotAfter: "2018-01-31 12:00:00 +0000 UTC", Status: StatusExpiring},
+		{DomainName: "failed.example.com", Status: StatusError, Error: `dial "x" failed`},
+	}
+	expected := `cert,domain=example.com,issuer=Example\ CA\,\ Inc\=1 days_remaining=30i,status="EXPIRING",error="" 1514764800000000000
+cert,domain=failed.example.com status="ERROR",error="dial \"x\" failed" 1514764800000000000
+`
+	if got := certs.Influx(); got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+}
@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPInfo tells where an IP address is served from.
+type IPInfo struct {
+	ASN          int    `json:"asn,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	// Country is the ISO 3166 code, e.g. "US".
+	Country string `json:"country,omitempty"`
+}
+
+// IPInfoProvider looks up the autonomous system and country of IP
+// addresses, e.g. from a GeoIP database or an online service.
+type IPInfoProvider interface {
+	IPInfo(ctx context.Context, ip net.IP) (*IPInfo, error)
+}
+
+// IPInfoFunc adapts a function to IPInfoProvider.
+type IPInfoFunc func(ctx context.Context, ip net.IP) (*IPInfo, error)
+
+// IPInfo calls f.
+func (f IPInfoFunc) IPInfo(ctx context.Context, ip net.IP) (*IPInfo, error) {
+	return f(ctx, ip)
+}
+
+// lookupIPInfo returns what provider knows about ip, or nil if the lookup
+// fails.
+func lookupIPInfo(ctx context.Context, opts *Options, provider IPInfoProvider, ip string) *IPInfo {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil
+	}
+	if t, ok := provider.(TeamCymru); ok && t.Resolver == nil {
+		t.Resolver = opts.resolver()
+		provider = t
+	}
+	_, span := startSpan(ctx, opts, "cert.ipinfo", "ip", ip)
+	info, err := provider.IPInfo(ctx, addr)
+	span.End(err)
+	if err != nil {
+		opts.logDebug("ip info lookup failed", "ip", ip, "err", err)
+		return nil
+	}
+	return info
+}
+
+// TeamCymru looks IP addresses up in the IP to ASN DNS service of Team
+// Cymru (https://www.team-cymru.com/ip-asn-mapping). Country is that of
+// the registration of the prefix.
+type TeamCymru struct {
+	// Resolver sends the TXT queries. Nil means Options.Resolver of the
+	// scan, or net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+// IPInfo looks ip up.
+func (t TeamCymru) IPInfo(ctx context.Context, ip net.IP) (*IPInfo, error) {
+	r := t.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	txt, err := r.LookupTXT(ctx, cymruOriginName(ip))
+	if err != nil {
+		return nil, err
+	}
+	if len(txt) == 0 {
+		return nil, fmt.Errorf("No origin of %s.", ip)
+	}
+	info, err := parseCymruOrigin(txt[0])
+	if err != nil {
+		return nil, err
+	}
+	if txt, err := r.LookupTXT(ctx, fmt.Sprintf("AS%d.asn.cymru.com", info.ASN)); err == nil && len(txt) > 0 {
+		info.Organization = parseCymruAS(txt[0])
+	}
+	return info, nil
+}
+
+// cymruOriginName returns the name to query for the origin of ip, its
+// reversed octets or nibbles under origin.asn.cymru.com or
+// origin6.asn.cymru.com.
+func cymruOriginName(ip net.IP) string {
+	var labels []string
+	if ip4 := ip.To4(); ip4 != nil {
+		for i := len(ip4) - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(ip4[i])))
+		}
+		return strings.Join(labels, ".") + ".origin.asn.cymru.com"
+	}
+	ip16 := ip.To16()
+	for i := len(ip16) - 1; i >= 0; i-- {
+		labels = append(labels, strconv.FormatUint(uint64(ip16[i]&0xf), 16), strconv.FormatUint(uint64(ip16[i]>>4), 16))
+	}
+	return strings.Join(labels, ".") + ".origin6.asn.cymru.com"
+}
+
+// parseCymruOrigin parses an origin record such as
+// "15169 | 8.8.8.0/24 | US | arin | 2014-03-14". Of several origin ASNs
+// the first is taken.
+func parseCymruOrigin(txt string) (*IPInfo, error) {
+	fields := strings.Split(txt, "|")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("Invalid origin record %q.", txt)
+	}
+	asns := strings.Fields(fields[0])
+	if len(asns) == 0 {
+		return nil, fmt.Errorf("Invalid origin record %q.", txt)
+	}
+	asn, err := strconv.Atoi(asns[0])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid origin record %q.", txt)
+	}
+	return &IPInfo{ASN: asn, Country: strings.TrimSpace(fields[2])}, nil
+}
+
+// parseCymruAS returns the name of the AS in a record such as
+// "15169 | US | arin | 2000-03-30 | GOOGLE - Google LLC, US".
+func parseCymruAS(txt string) string {
+	fields := strings.Split(txt, "|")
+	if len(fields) < 5 {
+		return ""
+	}
+	return strings.TrimSpace(fields[4])
+}
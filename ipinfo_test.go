@@ -0,0 +1,82 @@
+package cert
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNewCertIPInfo(t *testing.T) {
+	stubCert()
+
+	provider := IPInfoFunc(func(ctx context.Context, ip net.IP) (*IPInfo, error) {
+		if !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+			return nil, errors.New("unexpected IP " + ip.String())
+		}
+		return &IPInfo{ASN: 64496, Organization: "EXAMPLE-NET", Country: "JP"}, nil
+	})
+	c := NewCertWithOptions("example.com", &Options{IPInfo: provider})
+	if want := (&IPInfo{ASN: 64496, Organization: "EXAMPLE-NET", Country: "JP"}); !reflect.DeepEqual(c.IPInfo, want) {
+		t.Errorf(`unexpected IPInfo %+v, want %+v`, c.IPInfo, want)
+	}
+
+	failing := IPInfoFunc(func(ctx context.Context, ip net.IP) (*IPInfo, error) {
+		return nil, errors.New("unavailable")
+	})
+	if c := NewCertWithOptions("example.com", &Options{IPInfo: failing}); c.IPInfo != nil || c.Error != "" {
+		t.Errorf(`unexpected IPInfo %+v and error %q, want nil and ""`, c.IPInfo, c.Error)
+	}
+}
+
+func TestTeamCymruResolver(t *testing.T) {
+	stubCert()
+
+	queried := make(chan bool, 10)
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			queried <- true
+			return nil, errors.New("no DNS for tenant")
+		},
+	}
+	c := NewCertWithOptions("example.com", &Options{IPInfo: TeamCymru{}, Resolver: resolver})
+	if c.IPInfo != nil {
+		t.Errorf(`unexpected IPInfo %+v, want nil`, c.IPInfo)
+	}
+	if len(queried) == 0 {
+		t.Error(`unexpected lookup through another resolver, want Options.Resolver`)
+	}
+}
+
+func TestCymruOriginName(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.0.2.1", "1.2.0.192.origin.asn.cymru.com"},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.origin6.asn.cymru.com"},
+	}
+	for _, test := range tests {
+		if got := cymruOriginName(net.ParseIP(test.ip)); got != test.want {
+			t.Errorf(`unexpected name %q for %s, want %q`, got, test.ip, test.want)
+		}
+	}
+}
+
+func TestParseCymru(t *testing.T) {
+	info, err := parseCymruOrigin("15169 36040 | 8.8.8.0/24 | US | arin | 2014-03-14")
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if want := (&IPInfo{ASN: 15169, Country: "US"}); !reflect.DeepEqual(info, want) {
+		t.Errorf(`unexpected IPInfo %+v, want %+v`, info, want)
+	}
+	if _, err := parseCymruOrigin("not a record"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if got, want := parseCymruAS("15169 | US | arin | 2000-03-30 | GOOGLE - Google LLC, US"), "GOOGLE - Google LLC, US"; got != want {
+		t.Errorf(`unexpected organization %q, want %q`, got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package cert
+
+import (
+	"crypto/md5"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ja3s returns the JA3S fingerprint of the recorded ServerHello: the MD5 of
+// "version,cipher,extension-extension-...", all in decimal.
+func (c *recordingConn) ja3s() string {
+	sh, err := parseHello(c.read.Bytes(), 2)
+	if err != nil {
+		return ""
+	}
+	return ja3sHash(sh)
+}
+
+func ja3sHash(sh *hello) string {
+	exts := make([]string, len(sh.extensions))
+	for i, e := range sh.extensions {
+		exts[i] = strconv.Itoa(int(e))
+	}
+	s := fmt.Sprintf("%d,%d,%s", sh.version, sh.cipherSuites[0], strings.Join(exts, "-"))
+	return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+}
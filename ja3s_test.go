@@ -0,0 +1,37 @@
+package cert
+
+import (
+	"crypto/md5"
+	"crypto/tls"
+	"fmt"
+	"testing"
+)
+
+func TestJA3SHash(t *testing.T) {
+	sh := &hello{version: 771, cipherSuites: []uint16{4865}, extensions: []uint16{51, 43}}
+	want := fmt.Sprintf("%x", md5.Sum([]byte("771,4865,51-43")))
+	if got := ja3sHash(sh); got != want {
+		t.Errorf(`unexpected JA3S %q, want %q`, got, want)
+	}
+}
+
+func TestNewCertWithJA3S(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	host, port := startTLSServer(t, nil)
+	c := NewCertWithOptions(host+":"+port, &Options{InsecureSkipVerify: true})
+	if c.JA3S != "" {
+		t.Errorf(`unexpected Cert.JA3S %q, want %q`, c.JA3S, "")
+	}
+
+	opts := &Options{InsecureSkipVerify: true, JA3S: true, MaxVersion: tls.VersionTLS12}
+	first := NewCertWithOptions(host+":"+port, opts)
+	second := NewCertWithOptions(host+":"+port, opts)
+	if len(first.JA3S) != 32 {
+		t.Errorf(`unexpected Cert.JA3S %q, want an MD5 hex digest`, first.JA3S)
+	}
+	if first.JA3S != second.JA3S {
+		t.Errorf(`unexpected unstable JA3S %q and %q`, first.JA3S, second.JA3S)
+	}
+}
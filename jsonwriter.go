@@ -0,0 +1,56 @@
+package cert
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONWriter writes certificates to an io.Writer as the elements of a JSON
+// array, one at a time, e.g. from Scanner.ScanStream, so that large scans
+// are never marshalled at once. After Close the output is a JSON array as
+// returned by Certs.JSON.
+type JSONWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+// NewJSONWriter returns a JSONWriter writing to w.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w}
+}
+
+// Write appends c to the array. Once writing fails, Write and Close return
+// the error without writing anything.
+func (jw *JSONWriter) Write(c *Cert) error {
+	if jw.err != nil {
+		return jw.err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		jw.err = err
+		return err
+	}
+	sep := ","
+	if jw.n == 0 {
+		sep = "["
+	}
+	if _, jw.err = io.WriteString(jw.w, sep); jw.err == nil {
+		_, jw.err = jw.w.Write(data)
+	}
+	jw.n++
+	return jw.err
+}
+
+// Close ends the array. It does not close the underlying writer.
+func (jw *JSONWriter) Close() error {
+	if jw.err != nil {
+		return jw.err
+	}
+	end := "]"
+	if jw.n == 0 {
+		end = "[]"
+	}
+	_, jw.err = io.WriteString(jw.w, end)
+	return jw.err
+}
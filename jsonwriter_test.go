@@ -0,0 +1,45 @@
+package cert
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestJSONWriter(t *testing.T) {
+	certs := Certs{
+		{DomainName: "example.com", IP: "192.0.2.1", Status: StatusOK},
+		{DomainName: "example.org", Error: "connection refused", Status: StatusError},
+	}
+	for n := 0; n <= len(certs); n++ {
+		var b bytes.Buffer
+		w := NewJSONWriter(&b)
+		for _, c := range certs[:n] {
+			if err := w.Write(c); err != nil {
+				t.Fatalf(`unexpected err %s, want nil`, err.Error())
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if want := string(certs[:n].JSON()); b.String() != want {
+			t.Errorf(`unexpected output %q, want %q`, b.String(), want)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestJSONWriterError(t *testing.T) {
+	w := NewJSONWriter(failingWriter{})
+	if err := w.Write(&Cert{DomainName: "example.com"}); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if err := w.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf(`unexpected err %v, want "disk full"`, err)
+	}
+}
@@ -0,0 +1,20 @@
+package cert
+
+import "crypto/tls"
+
+// postQuantumGroups are hybrid key exchange groups which include a
+// post-quantum KEM.
+var postQuantumGroups = map[tls.CurveID]bool{
+	0x11EB: true, // SecP256r1MLKEM768
+	0x11EC: true, // X25519MLKEM768
+	0x11ED: true, // SecP384r1MLKEM1024
+	0x6399: true, // X25519Kyber768Draft00
+	0x639A: true, // SecP256r1Kyber768Draft00
+}
+
+func keyExchange(curve tls.CurveID) (string, bool) {
+	if curve == 0 {
+		return "", false
+	}
+	return curve.String(), postQuantumGroups[curve]
+}
@@ -0,0 +1,50 @@
+package cert
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestKeyExchange(t *testing.T) {
+	var tests = []struct {
+		curve tls.CurveID
+		name  string
+		pq    bool
+	}{
+		{0, "", false},
+		{tls.X25519, "X25519", false},
+		{tls.CurveP256, "CurveP256", false},
+		{tls.X25519MLKEM768, "X25519MLKEM768", true},
+	}
+	for _, test := range tests {
+		name, pq := keyExchange(test.curve)
+		if name != test.name || pq != test.pq {
+			t.Errorf("keyExchange(%d) = %q, %v, want %q, %v", test.curve, name, pq, test.name, test.pq)
+		}
+	}
+}
+
+func TestNewCertKeyExchange(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	// Set the groups on both ends rather than rely on the default, which
+	// depends on the GODEBUG of the build.
+	curves := []tls.CurveID{tls.X25519MLKEM768, tls.X25519}
+	host, port := startTLSServer(t, &tls.Config{CurvePreferences: curves})
+	c := NewCertWithOptions(host+":"+port, &Options{
+		InsecureSkipVerify: true,
+		TLSConfig:          &tls.Config{CurvePreferences: curves},
+	})
+	if c.KeyExchange != "X25519MLKEM768" || !c.PostQuantum {
+		t.Errorf(`unexpected key exchange %q, %v, want %q, %v`, c.KeyExchange, c.PostQuantum, "X25519MLKEM768", true)
+	}
+
+	c = NewCertWithOptions(host+":"+port, &Options{
+		InsecureSkipVerify: true,
+		TLSConfig:          &tls.Config{CurvePreferences: []tls.CurveID{tls.X25519}},
+	})
+	if c.KeyExchange != "X25519" || c.PostQuantum {
+		t.Errorf(`unexpected key exchange %q, %v, want %q, %v`, c.KeyExchange, c.PostQuantum, "X25519", false)
+	}
+}
@@ -0,0 +1,65 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ParsePrivateKey parses the first private key of PEM data, in PKCS #8,
+// PKCS #1 or SEC 1 form.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("No private key found.")
+		}
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid private key: %v.", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported private key %T.", key)
+		}
+		return signer, nil
+	}
+}
+
+// MatchesPrivateKey fetches the certificate of hostport and reports whether
+// it is issued for the PEM private key, e.g. to validate a deployment right
+// after rotating key and certificate. The error is that of the scan if it
+// failed.
+func MatchesPrivateKey(hostport string, keyPEM []byte, opts *Options) (bool, error) {
+	key, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return false, err
+	}
+	o := *opts.orDefault()
+	o.PrivateKey = key
+	c := NewCertWithOptions(hostport, &o)
+	if c.KeyMatch == nil {
+		return false, errors.New(c.Error)
+	}
+	return *c.KeyMatch, nil
+}
+
+// keyMatches reports whether cert is issued for the public key of key.
+func keyMatches(cert *x509.Certificate, key crypto.Signer) bool {
+	pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && pub.Equal(cert.PublicKey)
+}
@@ -0,0 +1,74 @@
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+
+	tests := []struct {
+		data []byte
+		key  interface{ Equal(crypto.PrivateKey) bool }
+	}{
+		{pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), ecKey},
+		{pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), rsaKey},
+		{pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), rsaKey},
+		{append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})...), ecKey},
+	}
+	for _, tt := range tests {
+		key, err := ParsePrivateKey(tt.data)
+		if err != nil || !tt.key.Equal(key) {
+			t.Errorf(`unexpected key %T and err %v, want %T`, key, err, tt.key)
+		}
+	}
+
+	if _, err := ParsePrivateKey([]byte("no key")); err == nil || err.Error() != "No private key found." {
+		t.Errorf(`unexpected err %v, want no private key`, err)
+	}
+	if _, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}})); err == nil {
+		t.Errorf(`unexpected err nil, want invalid private key`)
+	}
+}
+
+func TestMatchesPrivateKey(t *testing.T) {
+	ca := newTestCA(t, "Test CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{DNSNames: []string{"example.com"}}, ca)
+	stubChain(leaf.cert, ca.cert)
+	defer stubCert()
+
+	keyPEM := func(key *ecdsa.PrivateKey) []byte {
+		der, _ := x509.MarshalECPrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	}
+	if ok, err := MatchesPrivateKey("example.com", keyPEM(leaf.key), nil); !ok || err != nil {
+		t.Errorf(`unexpected match %v and err %v, want true`, ok, err)
+	}
+	if ok, err := MatchesPrivateKey("example.com", keyPEM(ca.key), nil); ok || err != nil {
+		t.Errorf(`unexpected match %v and err %v, want false`, ok, err)
+	}
+
+	c := NewCertWithOptions("example.com", &Options{PrivateKey: ca.key})
+	if fs := findings(c); len(fs) == 0 || fs[len(fs)-1].Rule != "key-mismatch" {
+		t.Errorf(`unexpected findings %+v, want key-mismatch`, fs)
+	}
+
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return nil, errors.New("connection refused")
+	}
+	if ok, err := MatchesPrivateKey("example.com", keyPEM(leaf.key), nil); ok || err == nil || err.Error() != "connection refused" {
+		t.Errorf(`unexpected match %v and err %v, want connection refused`, ok, err)
+	}
+}
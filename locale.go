@@ -0,0 +1,82 @@
+package cert
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Locale selects the language of labels and status words in the default
+// and markdown output. Unknown locales fall back to English.
+var Locale = "en"
+
+var labelOrder = []string{"DomainName", "IP", "Issuer", "NotBefore", "NotAfter", "CommonName", "SANs", "Error", "Warnings", "Pin", "Status"}
+
+var locales = map[string]map[string]string{
+	"ja": {
+		"DomainName":   "ドメイン名",
+		"Port":         "ポート",
+		"Issuer":       "発行者",
+		"NotBefore":    "有効期間開始",
+		"NotAfter":     "有効期間終了",
+		"CommonName":   "コモンネーム",
+		"Error":        "エラー",
+		"Warnings":     "警告",
+		"Pin":          "ピン",
+		"Status":       "状態",
+		StatusOK:       "正常",
+		StatusExpiring: "期限間近",
+		StatusExpired:  "期限切れ",
+		StatusError:    "エラー",
+	},
+	"de": {
+		"DomainName":   "Domainname",
+		"Issuer":       "Aussteller",
+		"NotBefore":    "Gültig ab",
+		"NotAfter":     "Gültig bis",
+		"CommonName":   "Common Name",
+		"Error":        "Fehler",
+		"Warnings":     "Warnungen",
+		StatusExpiring: "LÄUFT AB",
+		StatusExpired:  "ABGELAUFEN",
+		StatusError:    "FEHLER",
+	},
+}
+
+// RegisterLocale adds or replaces the translations of a locale. Keys are
+// Cert field names and Status values; missing keys are left in English.
+func RegisterLocale(locale string, translations map[string]string) {
+	locales[locale] = translations
+}
+
+func init() {
+	funcs["label"] = label
+	funcs["translate"] = translate
+}
+
+func translate(s string) string {
+	if t, ok := locales[Locale][s]; ok {
+		return t
+	}
+	return s
+}
+
+func label(name string) string {
+	width := 0
+	for _, l := range labelOrder {
+		if w := displayWidth(translate(l)); w > width {
+			width = w
+		}
+	}
+	l := translate(name) + ":"
+	return l + strings.Repeat(" ", width+2-displayWidth(l))
+}
+
+func displayWidth(s string) int {
+	w := utf8.RuneCountInString(s)
+	for _, r := range s {
+		if r >= 0x2E80 {
+			w++
+		}
+	}
+	return w
+}
@@ -0,0 +1,64 @@
+package cert
+
+import (
+	"testing"
+)
+
+func TestCertsAsStringInJapanese(t *testing.T) {
+	Locale = "ja"
+	defer func() { Locale = "en" }()
+
+	certs := Certs{{DomainName: "example.com", IP: "127.0.0.1", Status: StatusExpired}}
+
+	expected := `ドメイン名:   example.com
+IP:           127.0.0.1
+発行者:       
+有効期間開始: 
+有効期間終了: 
+コモンネーム: 
+SANs:         []
+エラー:       
+状態:         期限切れ
+
+
+`
+	if certs.String() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, certs.String(), expected)
+	}
+}
+
+func TestTranslateFallback(t *testing.T) {
+	Locale = "de"
+	defer func() { Locale = "en" }()
+
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{"Issuer", "Aussteller"},
+		{"Status", "Status"},
+		{StatusOK, StatusOK},
+		{StatusExpired, "ABGELAUFEN"},
+	}
+	for _, test := range tests {
+		if got := translate(test.input); got != test.want {
+			t.Errorf("translate(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+
+	Locale = "xx"
+	if got := translate("Issuer"); got != "Issuer" {
+		t.Errorf("translate(%q) = %q, want %q", "Issuer", got, "Issuer")
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	RegisterLocale("fr", map[string]string{"Issuer": "Émetteur"})
+	defer delete(locales, "fr")
+	Locale = "fr"
+	defer func() { Locale = "en" }()
+
+	if got := label("Issuer"); got != "Émetteur:   " {
+		t.Errorf("label(%q) = %q, want %q", "Issuer", got, "Émetteur:   ")
+	}
+}
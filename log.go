@@ -0,0 +1,29 @@
+package cert
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger receives debug level records about dial attempts, timeouts and
+// failures of scans without Options.Logger, and about sink retries.
+// Logging is disabled while it is nil.
+var Logger *slog.Logger
+
+// logger returns opts.Logger, or Logger if opts has none.
+func (opts *Options) logger() *slog.Logger {
+	if opts != nil && opts.Logger != nil {
+		return opts.Logger
+	}
+	return Logger
+}
+
+func (opts *Options) logDebug(msg string, args ...any) {
+	if l := opts.logger(); l != nil {
+		l.Log(context.Background(), slog.LevelDebug, msg, args...)
+	}
+}
+
+func logDebug(msg string, args ...any) {
+	(*Options)(nil).logDebug(msg, args...)
+}
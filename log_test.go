@@ -0,0 +1,57 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestLogDebug(t *testing.T) {
+	logDebug("nothing happens without a logger")
+
+	var b bytes.Buffer
+	Logger = slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	defer func() { Logger = nil }()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+
+	if _, err := dialAny(context.Background(), []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, port, nil); err == nil {
+		t.Fatal(`unexpected nil, want error`)
+	}
+
+	out := b.String()
+	if !strings.Contains(out, "msg=dialing addr=127.0.0.1:"+port) {
+		t.Errorf(`unexpected log %q, want dial attempt`, out)
+	}
+	if !strings.Contains(out, "msg=\"dial failed\" addr=127.0.0.1:"+port) {
+		t.Errorf(`unexpected log %q, want dial failure`, out)
+	}
+}
+
+func TestLogDebugOption(t *testing.T) {
+	var global, own bytes.Buffer
+	Logger = slog.New(slog.NewTextHandler(&global, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	defer func() { Logger = nil }()
+	opts := &Options{Logger: slog.New(slog.NewTextHandler(&own, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+
+	opts.logDebug("scan started")
+	if !strings.Contains(own.String(), "msg=\"scan started\"") {
+		t.Errorf(`unexpected log %q, want scan started`, own.String())
+	}
+	if global.Len() != 0 {
+		t.Errorf(`unexpected log %q, want none`, global.String())
+	}
+
+	(&Options{}).logDebug("scan finished")
+	if !strings.Contains(global.String(), "msg=\"scan finished\"") {
+		t.Errorf(`unexpected log %q, want scan finished`, global.String())
+	}
+}
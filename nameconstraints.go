@@ -0,0 +1,113 @@
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// nameConstraintViolations describes each SAN of the leaf of chain that the
+// name constraints of a certificate above it exclude or do not permit.
+func nameConstraintViolations(chain []*x509.Certificate) []string {
+	if len(chain) < 2 {
+		return nil
+	}
+	leaf := chain[0]
+	var violations []string
+	for _, ca := range chain[1:] {
+		check := func(kind, name string, permitted, excluded bool) {
+			switch {
+			case excluded:
+				violations = append(violations, fmt.Sprintf("%s %s is excluded by the name constraints of %s.", kind, name, ca.Subject.CommonName))
+			case !permitted:
+				violations = append(violations, fmt.Sprintf("%s %s is not permitted by the name constraints of %s.", kind, name, ca.Subject.CommonName))
+			}
+		}
+		for _, name := range leaf.DNSNames {
+			check("DNS name", name,
+				len(ca.PermittedDNSDomains) == 0 || anyMatch(ca.PermittedDNSDomains, name, matchDNSConstraint),
+				anyMatch(ca.ExcludedDNSDomains, name, matchDNSConstraint))
+		}
+		for _, ip := range leaf.IPAddresses {
+			check("IP address", ip.String(),
+				len(ca.PermittedIPRanges) == 0 || anyNet(ca.PermittedIPRanges, ip),
+				anyNet(ca.ExcludedIPRanges, ip))
+		}
+		for _, email := range leaf.EmailAddresses {
+			check("Email address", email,
+				len(ca.PermittedEmailAddresses) == 0 || anyMatch(ca.PermittedEmailAddresses, email, matchEmailConstraint),
+				anyMatch(ca.ExcludedEmailAddresses, email, matchEmailConstraint))
+		}
+		for _, uri := range leaf.URIs {
+			host := uri.Hostname()
+			check("URI", uri.String(),
+				len(ca.PermittedURIDomains) == 0 || anyMatch(ca.PermittedURIDomains, host, matchURIConstraint),
+				anyMatch(ca.ExcludedURIDomains, host, matchURIConstraint))
+		}
+	}
+	return violations
+}
+
+func anyMatch(constraints []string, name string, match func(name, constraint string) bool) bool {
+	for _, c := range constraints {
+		if match(name, c) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyNet(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDNSConstraint reports whether name is within constraint as RFC 5280
+// defines for DNS names: the domain itself and its subdomains, or only the
+// subdomains if constraint starts with a dot.
+func matchDNSConstraint(name, constraint string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	constraint = strings.ToLower(constraint)
+	if constraint == "" {
+		return true
+	}
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(name, constraint)
+	}
+	return name == constraint || strings.HasSuffix(name, "."+constraint)
+}
+
+// matchEmailConstraint reports whether email is within constraint: the
+// address itself if it has an @, otherwise addresses at the host, or at its
+// subdomains if constraint starts with a dot.
+func matchEmailConstraint(email, constraint string) bool {
+	if strings.Contains(constraint, "@") {
+		return strings.EqualFold(email, constraint)
+	}
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
+	}
+	host := strings.ToLower(email[i+1:])
+	constraint = strings.ToLower(constraint)
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(host, constraint)
+	}
+	return host == constraint
+}
+
+// matchURIConstraint reports whether host is within constraint: the host
+// itself, or its subdomains if constraint starts with a dot.
+func matchURIConstraint(host, constraint string) bool {
+	host = strings.ToLower(host)
+	constraint = strings.ToLower(constraint)
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(host, constraint)
+	}
+	return host == constraint
+}
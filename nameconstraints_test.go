@@ -0,0 +1,105 @@
+package cert
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNameConstraintViolations(t *testing.T) {
+	root := newTestCA(t, "Test Root", nil)
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Constrained CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		PermittedDNSDomains:   []string{"example.com"},
+		ExcludedDNSDomains:    []string{"internal.example.com"},
+		PermittedIPRanges:     []*net.IPNet{{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)}},
+	}, root)
+	leaf := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "www.example.com"},
+		DNSNames:    []string{"www.example.com", "a.internal.example.com", "example.org"},
+		IPAddresses: []net.IP{net.IPv4(10, 1, 2, 3), net.IPv4(192, 0, 2, 1)},
+	}, ca)
+
+	got := nameConstraintViolations([]*x509.Certificate{leaf.cert, ca.cert, root.cert})
+	want := []string{
+		"DNS name a.internal.example.com is excluded by the name constraints of Constrained CA.",
+		"DNS name example.org is not permitted by the name constraints of Constrained CA.",
+		"IP address 192.0.2.1 is not permitted by the name constraints of Constrained CA.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected violations %q, want %q`, got, want)
+	}
+
+	if got := nameConstraintViolations([]*x509.Certificate{ca.cert, root.cert}); got != nil {
+		t.Errorf(`unexpected violations %q, want nil`, got)
+	}
+	if got := nameConstraintViolations([]*x509.Certificate{leaf.cert}); got != nil {
+		t.Errorf(`unexpected violations %q, want nil`, got)
+	}
+
+	c := &Cert{chain: []*x509.Certificate{leaf.cert, ca.cert, root.cert}}
+	n := 0
+	for _, f := range findings(c) {
+		if f.Rule == "name-constraint" {
+			n++
+		}
+	}
+	if n != len(want) {
+		t.Errorf(`unexpected name-constraint findings %d, want %d`, n, len(want))
+	}
+}
+
+func TestMatchDNSConstraint(t *testing.T) {
+	tests := []struct {
+		name, constraint string
+		want             bool
+	}{
+		{"example.com", "example.com", true},
+		{"www.Example.com.", "example.com", true},
+		{"badexample.com", "example.com", false},
+		{"example.com", ".example.com", false},
+		{"www.example.com", ".example.com", true},
+		{"anything", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchDNSConstraint(tt.name, tt.constraint); got != tt.want {
+			t.Errorf(`unexpected match of %q and %q %v, want %v`, tt.name, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestMatchEmailConstraint(t *testing.T) {
+	tests := []struct {
+		email, constraint string
+		want              bool
+	}{
+		{"admin@example.com", "admin@example.com", true},
+		{"root@example.com", "admin@example.com", false},
+		{"admin@example.com", "example.com", true},
+		{"admin@mail.example.com", "example.com", false},
+		{"admin@mail.example.com", ".example.com", true},
+		{"invalid", "example.com", false},
+	}
+	for _, tt := range tests {
+		if got := matchEmailConstraint(tt.email, tt.constraint); got != tt.want {
+			t.Errorf(`unexpected match of %q and %q %v, want %v`, tt.email, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestMatchURIConstraint(t *testing.T) {
+	if !matchURIConstraint("example.com", "example.com") {
+		t.Errorf(`unexpected mismatch of "example.com" and "example.com"`)
+	}
+	if matchURIConstraint("www.example.com", "example.com") {
+		t.Errorf(`unexpected match of "www.example.com" and "example.com"`)
+	}
+	if !matchURIConstraint("www.example.com", ".example.com") {
+		t.Errorf(`unexpected mismatch of "www.example.com" and ".example.com"`)
+	}
+}
@@ -0,0 +1,110 @@
+package cert
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLadder is the lead-time ladder used when none is given.
+var DefaultLadder = []time.Duration{30 * 24 * time.Hour, 14 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour}
+
+// Notification is an expiry alert raised by a Ladder.
+type Notification struct {
+	// DomainName is followed by the port unless it is the default one.
+	DomainName string        `json:"domainName"`
+	NotAfter   string        `json:"notAfter"`
+	Threshold  time.Duration `json:"threshold"`
+	Remaining  time.Duration `json:"remaining"`
+}
+
+func (n Notification) String() string {
+	if n.Remaining < 0 {
+		return fmt.Sprintf("%s expired at %s.", n.DomainName, n.NotAfter)
+	}
+	return fmt.Sprintf("%s expires in %s at %s.", n.DomainName, humanizeDuration(n.Remaining), n.NotAfter)
+}
+
+// Ladder raises expiry notifications at a series of lead times. Each
+// certificate fires each threshold at most once, and a certificate first
+// seen close to expiry fires only the most urgent threshold it has crossed.
+// A renewed certificate starts over.
+type Ladder struct {
+	thresholds []time.Duration
+	fired      map[string]time.Duration
+}
+
+// NewLadder returns a Ladder for thresholds, or DefaultLadder if none.
+func NewLadder(thresholds ...time.Duration) *Ladder {
+	if len(thresholds) == 0 {
+		thresholds = DefaultLadder
+	}
+	t := append([]time.Duration(nil), thresholds...)
+	sort.Slice(t, func(i, j int) bool { return t[i] > t[j] })
+	return &Ladder{thresholds: t, fired: map[string]time.Duration{}}
+}
+
+// ParseLadder parses a comma separated list of days, e.g. "30,14,7,1".
+func ParseLadder(s string) ([]time.Duration, error) {
+	var thresholds []time.Duration
+	for _, f := range strings.Split(s, ",") {
+		days, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("Invalid number of days %q.", f)
+		}
+		thresholds = append(thresholds, time.Duration(days)*24*time.Hour)
+	}
+	return thresholds, nil
+}
+
+// Check returns the notifications due for certs that have not fired yet.
+// Expiry that Cert.Policy ignores is not notified. certs are all results
+// of a run: the thresholds fired by renewed certificates and by hosts no
+// longer in certs are forgotten, those of failed hosts kept.
+func (l *Ladder) Check(certs Certs) []Notification {
+	var ns []Notification
+	// current holds the key of each host, or "" if it failed.
+	current := map[string]string{}
+	for _, c := range certs {
+		notAfter, ok := c.expiry()
+		if c.Error != "" || !ok {
+			current[hostport(c)] = ""
+			continue
+		}
+		current[hostport(c)] = hostport(c) + "|" + c.NotAfter
+		remaining := notAfter.Sub(now())
+		if remaining > 0 && c.severity("expiring") == "ignore" || remaining <= 0 && c.severity("expired") == "ignore" {
+			continue
+		}
+		tier := -1
+		for i, t := range l.thresholds {
+			if remaining <= t {
+				tier = i
+			}
+		}
+		key := hostport(c) + "|" + c.NotAfter
+		if tier < 0 {
+			continue
+		}
+		if prev, ok := l.fired[key]; ok && prev <= l.thresholds[tier] {
+			continue
+		}
+		l.fired[key] = l.thresholds[tier]
+		ns = append(ns, Notification{
+			DomainName: hostport(c),
+			NotAfter:   c.NotAfter,
+			Threshold:  l.thresholds[tier],
+			Remaining:  remaining,
+		})
+	}
+
+	for key := range l.fired {
+		host, _, _ := strings.Cut(key, "|")
+		if k, ok := current[host]; !ok || k != "" && k != key {
+			delete(l.fired, key)
+		}
+	}
+	return ns
+}
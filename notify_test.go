@@ -0,0 +1,96 @@
+package cert
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLadder(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+	current := start
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "a.example.com", NotAfter: "2018-01-21 00:00:00 +0000 UTC"},
+		{DomainName: "b.example.com", NotAfter: "2018-01-04 00:00:00 +0000 UTC"},
+		{DomainName: "c.example.com", NotAfter: "2018-06-01 00:00:00 +0000 UTC"},
+		{DomainName: "d.example.com", Error: "connection refused"},
+	}
+	l := NewLadder(7*day, 30*day, 14*day, day)
+
+	fired := func() []string {
+		var got []string
+		for _, n := range l.Check(certs) {
+			got = append(got, n.DomainName+" "+humanizeDuration(n.Threshold))
+		}
+		return got
+	}
+
+	var tests = []struct {
+		at   time.Time
+		want []string
+	}{
+		{start, []string{"a.example.com 30 days", "b.example.com 7 days"}},
+		{start.Add(time.Hour), nil},
+		{start.Add(2*day + time.Hour), []string{"b.example.com 24 hours"}},
+		{start.Add(7 * day), []string{"a.example.com 14 days"}},
+		{start.Add(8 * day), nil},
+		{start.Add(14 * day), []string{"a.example.com 7 days"}},
+	}
+	for _, test := range tests {
+		current = test.at
+		if got := fired(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf(`unexpected notifications %q at %s, want %q`, got, test.at, test.want)
+		}
+	}
+
+	certs[0].NotAfter = "2018-04-15 00:00:00 +0000 UTC"
+	current = start.Add(20 * day)
+	if got := fired(); got != nil {
+		t.Errorf(`unexpected notifications %q after renewal, want none`, got)
+	}
+	if _, ok := l.fired["a.example.com|2018-01-21 00:00:00 +0000 UTC"]; ok {
+		t.Error(`unexpected threshold kept for the renewed certificate`)
+	}
+
+	// A failed host keeps its thresholds, a removed one loses them.
+	certs[1].Error = "connection refused"
+	l.Check(certs[1:])
+	if _, ok := l.fired["b.example.com|2018-01-04 00:00:00 +0000 UTC"]; !ok {
+		t.Error(`unexpected threshold dropped for the failed host`)
+	}
+	l.Check(certs[2:])
+	if len(l.fired) != 0 {
+		t.Errorf(`unexpected thresholds %v kept for removed hosts`, l.fired)
+	}
+}
+
+func TestParseLadder(t *testing.T) {
+	got, err := ParseLadder("30, 14,7,1")
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	day := 24 * time.Hour
+	if want := []time.Duration{30 * day, 14 * day, 7 * day, day}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected thresholds %v, want %v`, got, want)
+	}
+	for _, s := range []string{"", "30,x", "-1"} {
+		if _, err := ParseLadder(s); err == nil {
+			t.Errorf(`unexpected nil for %q, want error`, s)
+		}
+	}
+}
+
+func TestNotificationString(t *testing.T) {
+	n := Notification{DomainName: "example.com", NotAfter: "2018-01-08 00:00:00 +0000 UTC", Remaining: 7 * 24 * time.Hour}
+	if want := "example.com expires in 7 days at 2018-01-08 00:00:00 +0000 UTC."; n.String() != want {
+		t.Errorf(`unexpected return value %q, want %q`, n.String(), want)
+	}
+	n.Remaining = -time.Hour
+	if want := "example.com expired at 2018-01-08 00:00:00 +0000 UTC."; n.String() != want {
+		t.Errorf(`unexpected return value %q, want %q`, n.String(), want)
+	}
+}
@@ -0,0 +1,288 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Options configure how certificates are fetched.
+// A nil *Options means DefaultOptions().
+type Options struct {
+	// InsecureSkipVerify skips verification of the server's certificate
+	// chain and host name.
+	InsecureSkipVerify bool
+
+	// ServerName is sent as SNI and verified instead of the target host.
+	ServerName string
+
+	// MinVersion and MaxVersion bound the TLS versions offered, e.g.
+	// tls.VersionTLS13. Zero leaves the crypto/tls default. A MaxVersion
+	// below that default without a MinVersion offers MaxVersion only.
+	MinVersion uint16
+	MaxVersion uint16
+
+	// CipherSuites restricts the offered TLS 1.0-1.2 cipher suites. It may
+	// include suites from tls.InsecureCipherSuites. TLS 1.3 suites are not
+	// configurable, so set MaxVersion to tls.VersionTLS12 to test them
+	// reliably.
+	CipherSuites []uint16
+
+	// TLSConfig is the base configuration for the probe connection. It is
+	// cloned, its ServerName defaults to the target host and the options
+	// above override it when set.
+	TLSConfig *tls.Config
+
+	// Extensions lists every extension of the leaf certificate in
+	// Cert.Extensions.
+	Extensions bool
+
+	// ChainPaths reports in Cert.ChainPaths every path from the leaf to a
+	// root in TLSConfig.RootCAs, or the system roots if unset.
+	ChainPaths bool
+
+	// CheckRevocation looks the leaf up in the CRLs it points to.
+	// RevocationPolicy decides whether an unavailable CRL is an error.
+	CheckRevocation  bool
+	RevocationPolicy RevocationPolicy
+
+	// JA3S fingerprints the server's ServerHello into Cert.JA3S.
+	JA3S bool
+
+	// PTR looks up the reverse DNS names of the connected IP into
+	// Cert.PTR, e.g. to tell which CDN or provider terminated TLS.
+	PTR bool
+
+	// IPInfo, if set, looks up the AS and country of the connected IP into
+	// Cert.IPInfo, e.g. TeamCymru{}.
+	IPInfo IPInfoProvider
+
+	// CompareNoSNI handshakes a second time without SNI and records in
+	// Cert.NoSNI whether the server returns another, default certificate,
+	// as legacy clients without SNI would get.
+	CompareNoSNI bool
+
+	// ServerNames are sent as SNI in one more handshake each with the
+	// address connected to, recording the certificate returned for each in
+	// Cert.SNIProbes, e.g. to enumerate the virtual hosts of a shared
+	// frontend.
+	ServerNames []string
+
+	// CheckFallbackSCSV handshakes once more at a lower TLS version with
+	// TLS_FALLBACK_SCSV and records in Cert.DowngradeProtection whether
+	// the server refuses, as RFC 7507 requires.
+	CheckFallbackSCSV bool
+
+	// Pins are the expected certificates of hosts, as SPKI pins such as
+	// "sha256/base64" or hex SHA-256 certificate fingerprints. A host
+	// passes if any certificate of its chain matches one of its pins, see
+	// Cert.Pin. Hosts without pins are not checked.
+	Pins map[string][]string
+
+	// PrivateKey, if set, checks that the leaf is issued for this key, in
+	// Cert.KeyMatch. See ParsePrivateKey.
+	PrivateKey crypto.Signer
+
+	// CSR, if set, compares the leaf to this certificate signing request
+	// into Cert.CSRDiffs, e.g. to verify that a CA issued what was
+	// requested. See ParseCSR.
+	CSR *x509.CertificateRequest
+
+	// CTLogs are the logs the SCTs embedded in the leaf, listed in
+	// Cert.SCTs, are verified against. See LoadCTLogs. Nil means the
+	// snapshot of the log list embedded at build time, and SCTs stay
+	// unverified if it is empty.
+	CTLogs CTLogs
+
+	// HSTS sends a HEAD request after the handshake and records the
+	// Strict-Transport-Security header in Cert.HSTS. It is skipped for
+	// targets negotiating STARTTLS.
+	HSTS bool
+
+	// SSH also fetches the SSH host key on port 22 of each host into
+	// Cert.SSHHostKey.
+	SSH bool
+
+	// FollowRedirects makes NewCertsWithOptions request every URL target,
+	// record its redirect chain in Cert.Redirects and add each HTTPS host
+	// on the way as an implicit target.
+	FollowRedirects bool
+
+	// Timeout bounds lookup, dial and handshake of each target. Zero
+	// means no timeout.
+	Timeout time.Duration
+
+	// FallbackDelay is how long a connection attempt to one address of a
+	// host may take before the next address is tried in parallel, as in
+	// RFC 8305 Happy Eyeballs. Zero means 250ms, negative tries addresses
+	// one after another.
+	FallbackDelay time.Duration
+
+	// LocalAddr is the local IP address, or the name of the network
+	// interface, to connect from.
+	LocalAddr string
+
+	// Proxy returns the HTTP proxy to connect to host through, or nil to
+	// connect directly. See ProxyMap and ProxyFromEnvironment, the default.
+	// Cert.IP is empty for proxied targets.
+	Proxy func(host string) (*url.URL, error)
+
+	// StartTLS is the protocol to negotiate TLS with before the handshake:
+	// smtp, pop3, imap, ldap or postgres. Empty picks it by port, 25 and
+	// 587 smtp, 110 pop3, 143 imap, 389 ldap and 5432 postgres, and
+	// StartTLSNone never negotiates.
+	StartTLS string
+
+	// Concurrency limits the targets scanned at once by one call. Zero
+	// shares a limit of 128 with all other calls.
+	Concurrency int
+
+	// Resolver looks up host names. Nil means net.DefaultResolver.
+	Resolver *net.Resolver
+
+	// ExpiringThreshold is how long before expiry a certificate gets
+	// StatusExpiring. Zero means the package level ExpiringThreshold.
+	ExpiringThreshold time.Duration
+
+	// MaxSANs limits the SANs shown per certificate in String and Markdown
+	// output, followed by a "(+N more)" marker. JSON output and templates
+	// always get every SAN. Zero shows all.
+	MaxSANs int
+
+	// UnicodeSANs shows xn-- SANs in String and Markdown output in their
+	// Unicode form followed by the A-label, e.g. "münchen.example
+	// (xn--mnchen-3ya.example)".
+	UnicodeSANs bool
+
+	// MaxWidth truncates the issuer, common name, SAN list and error shown
+	// in String, Markdown and Table output to this many characters, ending
+	// in "…". In Markdown each SAN is truncated on its own. Zero shows them
+	// in full.
+	MaxWidth int
+
+	// FailFast aborts NewCertsWithOptions and NewCertsFromTargets on the
+	// first failure, cancelling the targets still being scanned.
+	FailFast FailFast
+
+	// Checkers run in order on every fetched certificate and add their
+	// findings to Cert.Findings.
+	Checkers []Checker
+
+	// Policy overrides the severity of findings.
+	Policy *Policy
+
+	// Tracer, if set, records a span per scan, per target and per resolve,
+	// dial and handshake.
+	Tracer Tracer
+
+	// Debug captures details of the TLS handshake into Cert.Debug.
+	Debug bool
+
+	// Logger receives debug level records about dial attempts, timeouts and
+	// failures. Nil means the package level Logger.
+	Logger *slog.Logger
+}
+
+// DefaultOptions returns Options initialized from the package level
+// defaults.
+func DefaultOptions() *Options {
+	return &Options{
+		InsecureSkipVerify: SkipVerify,
+		Proxy:              ProxyFromEnvironment,
+	}
+}
+
+// ParseTLSVersion parses a version such as "1.2" into a tls.VersionTLS* value.
+func ParseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("Unknown TLS version %q.", s)
+}
+
+// ParseCipherSuites parses a comma separated list of cipher suite names
+// such as "TLS_RSA_WITH_AES_128_CBC_SHA,TLS_RSA_WITH_3DES_EDE_CBC_SHA".
+func ParseCipherSuites(s string) ([]uint16, error) {
+	ids := map[string]uint16{}
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		ids[suite.Name] = suite.ID
+	}
+
+	var suites []uint16
+	for _, name := range strings.Split(s, ",") {
+		id, ok := ids[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown cipher suite %q.", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
+
+func (opts *Options) tlsConfig(host string) *tls.Config {
+	config := &tls.Config{}
+	if opts.TLSConfig != nil {
+		config = opts.TLSConfig.Clone()
+	}
+	if opts.ServerName != "" {
+		config.ServerName = opts.ServerName
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	if opts.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+	if opts.MinVersion != 0 {
+		config.MinVersion = opts.MinVersion
+	}
+	if opts.MaxVersion != 0 {
+		config.MaxVersion = opts.MaxVersion
+		// crypto/tls offers TLS 1.2 at least by default.
+		if config.MinVersion == 0 && config.MaxVersion < tls.VersionTLS12 {
+			config.MinVersion = config.MaxVersion
+		}
+	}
+	if opts.CipherSuites != nil {
+		config.CipherSuites = opts.CipherSuites
+	}
+	return config
+}
+
+func (opts *Options) resolver() *net.Resolver {
+	if opts.Resolver != nil {
+		return opts.Resolver
+	}
+	return net.DefaultResolver
+}
+
+func (opts *Options) expiringThreshold() time.Duration {
+	if opts.ExpiringThreshold != 0 {
+		return opts.ExpiringThreshold
+	}
+	return ExpiringThreshold
+}
+
+func (opts *Options) debug() bool {
+	return opts.Debug || Debug
+}
+
+func (opts *Options) orDefault() *Options {
+	if opts == nil {
+		return DefaultOptions()
+	}
+	return opts
+}
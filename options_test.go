@@ -0,0 +1,137 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"reflect"
+	"testing"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  uint16
+	}{
+		{"1.0", tls.VersionTLS10},
+		{"1.1", tls.VersionTLS11},
+		{"1.2", tls.VersionTLS12},
+		{"TLS1.3", tls.VersionTLS13},
+	}
+	for _, test := range tests {
+		got, err := ParseTLSVersion(test.input)
+		if err != nil || got != test.want {
+			t.Errorf("ParseTLSVersion(%q) = %x, %v, want %x", test.input, got, err, test.want)
+		}
+	}
+	if _, err := ParseTLSVersion("1.4"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestServerCertWithMaxVersion(t *testing.T) {
+	host, port := startTLSServer(t, nil)
+	info, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true, Debug: true, MaxVersion: tls.VersionTLS12})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if info.diagnostic.Version != "TLS 1.2" {
+		t.Errorf(`unexpected version %q, want %q`, info.diagnostic.Version, "TLS 1.2")
+	}
+}
+
+func TestServerCertWithMaxVersionTLS10(t *testing.T) {
+	host, port := startTLSServer(t, &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS10})
+	info, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true, Debug: true, MaxVersion: tls.VersionTLS10})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if info.diagnostic.Version != "TLS 1.0" {
+		t.Errorf(`unexpected version %q, want %q`, info.diagnostic.Version, "TLS 1.0")
+	}
+
+	config := (&Options{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}).tlsConfig("example.com")
+	if config.MinVersion != tls.VersionTLS10 {
+		t.Errorf(`unexpected MinVersion %x, want %x`, config.MinVersion, tls.VersionTLS10)
+	}
+	if config := (&Options{MaxVersion: tls.VersionTLS13}).tlsConfig("example.com"); config.MinVersion != 0 {
+		t.Errorf(`unexpected MinVersion %x, want 0`, config.MinVersion)
+	}
+}
+
+func TestServerCertWithMinVersion(t *testing.T) {
+	host, port := startTLSServer(t, &tls.Config{MaxVersion: tls.VersionTLS12})
+	_, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true, MinVersion: tls.VersionTLS13})
+	if err == nil {
+		t.Fatal(`unexpected nil, want error`)
+	}
+	if kind := errorKind(err); kind != ErrorKindTLS {
+		t.Errorf(`unexpected error kind %q, want %q`, kind, ErrorKindTLS)
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	suites, err := ParseCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, TLS_RSA_WITH_3DES_EDE_CBC_SHA")
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	want := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA}
+	if !reflect.DeepEqual(suites, want) {
+		t.Errorf(`unexpected suites %v, want %v`, suites, want)
+	}
+	if _, err := ParseCipherSuites("TLS_NO_SUCH_SUITE"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestServerCertWithCipherSuites(t *testing.T) {
+	host, port := startTLSServer(t, nil)
+	info, err := realServerCert(context.Background(), host, port, &Options{
+		InsecureSkipVerify: true,
+		Debug:              true,
+		MaxVersion:         tls.VersionTLS12,
+		CipherSuites:       []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+	})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if info.diagnostic.CipherSuite != "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384" {
+		t.Errorf(`unexpected cipher suite %q, want %q`, info.diagnostic.CipherSuite, "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+	}
+}
+
+func TestOptionsTLSConfig(t *testing.T) {
+	base := &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519},
+	}
+	opts := &Options{TLSConfig: base, MaxVersion: tls.VersionTLS12}
+
+	config := opts.tlsConfig("example.com")
+	if config == base {
+		t.Error(`unexpected base config, want a clone`)
+	}
+	if config.ServerName != "example.com" {
+		t.Errorf(`unexpected ServerName %q, want %q`, config.ServerName, "example.com")
+	}
+	if config.MinVersion != tls.VersionTLS12 || config.MaxVersion != tls.VersionTLS12 {
+		t.Errorf(`unexpected versions %x-%x, want %x-%x`, config.MinVersion, config.MaxVersion, tls.VersionTLS12, tls.VersionTLS12)
+	}
+	if !reflect.DeepEqual(config.CurvePreferences, base.CurvePreferences) {
+		t.Errorf(`unexpected CurvePreferences %v, want %v`, config.CurvePreferences, base.CurvePreferences)
+	}
+	if base.ServerName != "" || base.MaxVersion != 0 {
+		t.Error(`unexpected modification of the base config`)
+	}
+
+	base.ServerName = "sni.example.com"
+	if config := opts.tlsConfig("example.com"); config.ServerName != "sni.example.com" {
+		t.Errorf(`unexpected ServerName %q, want %q`, config.ServerName, "sni.example.com")
+	}
+}
+
+func TestOptionsServerName(t *testing.T) {
+	opts := &Options{ServerName: "sni.example.com", TLSConfig: &tls.Config{ServerName: "base.example.com"}}
+	if config := opts.tlsConfig("example.com"); config.ServerName != "sni.example.com" {
+		t.Errorf(`unexpected ServerName %q, want %q`, config.ServerName, "sni.example.com")
+	}
+}
@@ -0,0 +1,98 @@
+package cert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// formats renders certs in the formats known to Render, by name.
+var formats = map[string]func(Certs) ([]byte, error){
+	"text":    func(certs Certs) ([]byte, error) { return []byte(certs.String()), nil },
+	"md":      func(certs Certs) ([]byte, error) { return []byte(certs.Markdown()), nil },
+	"table":   func(certs Certs) ([]byte, error) { return []byte(certs.Table()), nil },
+	"json":    func(certs Certs) ([]byte, error) { return certs.JSON(), nil },
+	"cef":     func(certs Certs) ([]byte, error) { return []byte(certs.CEF()), nil },
+	"dot":     func(certs Certs) ([]byte, error) { return []byte(certs.DOT()), nil },
+	"pdf":     func(certs Certs) ([]byte, error) { return certs.PDF(), nil },
+	"proto":   func(certs Certs) ([]byte, error) { return certs.Proto(), nil },
+	"sarif":   func(certs Certs) ([]byte, error) { return certs.SARIF(), nil },
+	"influx":  func(certs Certs) ([]byte, error) { return []byte(certs.Influx()), nil },
+	"ical":    func(certs Certs) ([]byte, error) { return []byte(certs.ICal(ExpiringThreshold)), nil },
+	"issuers": func(certs Certs) ([]byte, error) { return []byte(certs.ByIssuer().String()), nil },
+	"histogram": func(certs Certs) ([]byte, error) {
+		h, err := certs.ExpiryHistogram(Monthly)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(h.String()), nil
+	},
+	"san-csv":  func(certs Certs) ([]byte, error) { return []byte(certs.SANRows().CSV()), nil },
+	"san-json": func(certs Certs) ([]byte, error) { return certs.SANRows().JSON(), nil },
+	"zabbix":   func(certs Certs) ([]byte, error) { return certs.Zabbix(), nil },
+	"tlsa": func(certs Certs) ([]byte, error) {
+		s, err := certs.TLSA(3, 1, 1)
+		return []byte(s), err
+	},
+}
+
+// RegisterFormat makes fn available to Render, WriteOutputs and the
+// outputs of a Config as the format name, replacing any format already
+// registered under that name. It is not safe to call concurrently with
+// rendering; call it during init.
+func RegisterFormat(name string, fn func(Certs, io.Writer) error) {
+	formats[name] = func(certs Certs) ([]byte, error) {
+		var b bytes.Buffer
+		err := fn(certs, &b)
+		return b.Bytes(), err
+	}
+}
+
+// Formats returns the names of the formats known to Render, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render returns certs in the named format, one of Formats.
+func (certs Certs) Render(format string) ([]byte, error) {
+	render, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("Unknown format %q.", format)
+	}
+	return render(certs)
+}
+
+// Output is a destination for one format.
+type Output struct {
+	Format string
+	Writer io.Writer
+}
+
+// WriteOutputs renders certs once per distinct format and writes the result
+// to each output, e.g. JSON to a file and text to stdout. All formats are
+// rendered before anything is written.
+func (certs Certs) WriteOutputs(outputs ...Output) error {
+	rendered := map[string][]byte{}
+	for _, o := range outputs {
+		if _, ok := rendered[o.Format]; ok {
+			continue
+		}
+		data, err := certs.Render(o.Format)
+		if err != nil {
+			return err
+		}
+		rendered[o.Format] = data
+	}
+	for _, o := range outputs {
+		if _, err := o.Writer.Write(rendered[o.Format]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCertsRender(t *testing.T) {
+	certs := Certs{{DomainName: "example.com", SANs: []string{"*.example.com"}, Status: StatusOK}}
+
+	for _, name := range Formats() {
+		if name == "tlsa" {
+			continue
+		}
+		if _, err := certs.Render(name); err != nil {
+			t.Errorf(`unexpected err %s for %q, want nil`, err.Error(), name)
+		}
+	}
+	if _, err := certs.Render("xml"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+
+	got, _ := certs.Render("json")
+	if !bytes.Equal(got, certs.JSON()) {
+		t.Errorf(`unexpected return value %q, want %q`, got, certs.JSON())
+	}
+	got, _ = certs.Render("ical")
+	if want := certs.ICal(ExpiringThreshold); string(got) != want {
+		t.Errorf(`unexpected return value %q, want %q`, got, want)
+	}
+}
+
+func TestCertsWriteOutputs(t *testing.T) {
+	certs := Certs{{DomainName: "example.com", SANs: []string{"*.example.com"}, Status: StatusOK}}
+
+	var md, js, js2, text bytes.Buffer
+	err := certs.WriteOutputs(
+		Output{"md", &md},
+		Output{"json", &js},
+		Output{"text", &text},
+		Output{"json", &js2},
+	)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if !strings.Contains(md.String(), `\*.example.com`) {
+		t.Errorf(`unexpected markdown %q, want escaped SAN`, md.String())
+	}
+	if !strings.Contains(js.String(), `"*.example.com"`) || js.String() != js2.String() {
+		t.Errorf(`unexpected json %q and %q, want unescaped SAN`, js.String(), js2.String())
+	}
+	if text.String() != certs.String() {
+		t.Errorf(`unexpected text %q, want %q`, text.String(), certs.String())
+	}
+	if !reflect.DeepEqual(certs[0].SANs, []string{"*.example.com"}) {
+		t.Errorf(`unexpected SANs %q after rendering markdown`, certs[0].SANs)
+	}
+
+	var unused bytes.Buffer
+	if err := certs.WriteOutputs(Output{"json", &unused}, Output{"xml", &unused}); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if unused.Len() != 0 {
+		t.Errorf(`unexpected output %q, want nothing written on error`, unused.String())
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	defer delete(formats, "domains")
+	RegisterFormat("domains", func(certs Certs, w io.Writer) error {
+		for _, c := range certs {
+			if _, err := fmt.Fprintln(w, c.DomainName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	certs := Certs{{DomainName: "example.com"}, {DomainName: "example.org"}}
+
+	got, err := certs.Render("domains")
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if string(got) != "example.com\nexample.org\n" {
+		t.Errorf(`unexpected return value %q, want %q`, got, "example.com\nexample.org\n")
+	}
+	if names := strings.Join(Formats(), ","); !strings.Contains(names, "domains") {
+		t.Errorf(`unexpected formats %s, want domains`, names)
+	}
+
+	var b bytes.Buffer
+	if err := certs.WriteOutputs(Output{"domains", &b}); err != nil || b.String() != string(got) {
+		t.Errorf(`unexpected output %q, %v`, b.String(), err)
+	}
+
+	RegisterFormat("domains", func(certs Certs, w io.Writer) error { return errors.New("asset DB unavailable") })
+	if _, err := certs.Render("domains"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
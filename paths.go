@@ -0,0 +1,46 @@
+package cert
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// ChainPath is one way of chaining the leaf to a trusted root.
+type ChainPath struct {
+	Subjects     []string `json:"subjects"`
+	Root         string   `json:"root"`
+	RootNotAfter string   `json:"rootNotAfter"`
+}
+
+// chainPaths returns every path from the leaf through the presented
+// certificates to a root in roots (the system pool if nil).
+func chainPaths(chain []*x509.Certificate, roots *x509.CertPool) []ChainPath {
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	verified, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]ChainPath, len(verified))
+	for i, v := range verified {
+		root := v[len(v)-1]
+		subjects := make([]string, len(v))
+		for j, c := range v {
+			subjects[j] = c.Subject.CommonName
+		}
+		paths[i] = ChainPath{
+			Subjects:     subjects,
+			Root:         root.Subject.CommonName,
+			RootNotAfter: root.NotAfter.In(time.Local).String(),
+		}
+	}
+	return paths
+}
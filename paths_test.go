@@ -0,0 +1,57 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNewCertWithChainPaths(t *testing.T) {
+	oldRoot := newTestCA(t, "Old Root", nil)
+	newRoot := newTestCA(t, "New Root", nil)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intermediateTemplate := func() *x509.Certificate {
+		return &x509.Certificate{
+			Subject:               pkix.Name{CommonName: "Intermediate CA"},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+	intermediate := newTestCertWithKey(t, intermediateTemplate(), newRoot, key)
+	crossSigned := newTestCertWithKey(t, intermediateTemplate(), oldRoot, key)
+	leaf := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "example.com"}}, intermediate)
+
+	stubChain(leaf.cert, intermediate.cert, crossSigned.cert)
+	defer stubCert()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(oldRoot.cert)
+	roots.AddCert(newRoot.cert)
+
+	if c := NewCert("example.com"); c.ChainPaths != nil {
+		t.Errorf(`unexpected Cert.ChainPaths %v, want nil`, c.ChainPaths)
+	}
+
+	c := NewCertWithOptions("example.com", &Options{ChainPaths: true, TLSConfig: &tls.Config{RootCAs: roots}})
+	var got []string
+	for _, p := range c.ChainPaths {
+		if !reflect.DeepEqual(p.Subjects, []string{"example.com", "Intermediate CA", p.Root}) {
+			t.Errorf(`unexpected subjects %v`, p.Subjects)
+		}
+		got = append(got, p.Root)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"New Root", "Old Root"}) {
+		t.Errorf(`unexpected roots %v, want %v`, got, []string{"New Root", "Old Root"})
+	}
+}
@@ -0,0 +1,167 @@
+package cert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Layout of PDF output on A4 landscape, in points.
+const (
+	pdfWidth     = 842
+	pdfHeight    = 595
+	pdfMargin    = 40
+	pdfFontSize  = 8
+	pdfRowHeight = 14
+)
+
+// pdfColumn is a column of the PDF table.
+type pdfColumn struct {
+	name  string
+	width float64
+	value func(c *Cert) string
+}
+
+var pdfColumns = []pdfColumn{
+	{"DomainName", 170, func(c *Cert) string { return c.DomainName }},
+	{"IP", 90, func(c *Cert) string { return c.IP }},
+	{"Issuer", 170, func(c *Cert) string { return c.Issuer }},
+	{"NotAfter", 130, func(c *Cert) string { return c.NotAfter }},
+	{"Status", 60, func(c *Cert) string { return c.Status }},
+	{"Error", 142, func(c *Cert) string { return c.Error }},
+}
+
+// pdfStatusColors are the RGB text colors of the statuses.
+var pdfStatusColors = map[string]string{
+	StatusOK:       "0 0.5 0",
+	StatusExpiring: "0.85 0.5 0",
+	StatusExpired:  "0.8 0 0",
+	StatusError:    "0.8 0 0",
+}
+
+// PDF renders certs as a PDF document with a summary header and a table
+// of one row per certificate, its status in color. Text is set in the
+// standard Helvetica font, so characters outside Latin-1 show as '?'.
+func (certs Certs) PDF() []byte {
+	statuses := map[string]int{}
+	for _, c := range certs {
+		statuses[c.Status]++
+	}
+
+	var pages []string
+	var page strings.Builder
+	y := float64(pdfHeight - pdfMargin)
+	text := func(font string, size, x, y float64, color, s string) {
+		fmt.Fprintf(&page, "BT /%s %g Tf %s rg %g %g Td (%s) Tj ET\n", font, size, color, x, y, pdfString(s))
+	}
+	header := func() {
+		x := float64(pdfMargin)
+		for _, col := range pdfColumns {
+			text("F2", pdfFontSize, x, y, "0 0 0", col.name)
+			x += col.width
+		}
+		fmt.Fprintf(&page, "0.5 w %d %g m %d %g l S\n", pdfMargin, y-4, pdfWidth-pdfMargin, y-4)
+		y -= pdfRowHeight + 2
+	}
+
+	text("F2", 16, pdfMargin, y-12, "0 0 0", "Certificate inventory")
+	y -= 32
+	text("F1", 10, pdfMargin, y, "0 0 0", fmt.Sprintf("Generated at %s, %d targets", now().Format(timeLayout), len(certs)))
+	y -= 14
+	var summary []string
+	for _, status := range []string{StatusOK, StatusExpiring, StatusExpired, StatusError} {
+		summary = append(summary, fmt.Sprintf("%s: %d", status, statuses[status]))
+	}
+	text("F1", 10, pdfMargin, y, "0 0 0", strings.Join(summary, ", "))
+	y -= 28
+	header()
+
+	for _, c := range certs {
+		if y < pdfMargin+pdfRowHeight {
+			pages = append(pages, page.String())
+			page.Reset()
+			y = pdfHeight - pdfMargin
+			header()
+		}
+		x := float64(pdfMargin)
+		for _, col := range pdfColumns {
+			color := "0 0 0"
+			if col.name == "Status" {
+				if rgb, ok := pdfStatusColors[c.Status]; ok {
+					color = rgb
+				}
+			}
+			text("F1", pdfFontSize, x, y, color, pdfFit(col.value(c), col.width))
+			x += col.width
+		}
+		y -= pdfRowHeight
+	}
+	pages = append(pages, page.String())
+
+	return pdfDocument(pages)
+}
+
+// pdfDocument assembles a PDF with one page per content stream.
+func pdfDocument(pages []string) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\nendobj\n")
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	// Objects 1 to 4 are the catalog, the page tree and the fonts, followed
+	// by a page and its contents for each page.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		footer := fmt.Sprintf("BT /F1 %d Tf 0 0 0 rg %d %d Td (Page %d of %d) Tj ET\n", pdfFontSize, pdfWidth-pdfMargin-50, pdfMargin/2, i+1, len(pages))
+		content += footer
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pdfWidth, pdfHeight, 6+2*i)
+		object("<< /Length %d >>\nstream\n%sendstream", len(content), content)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// pdfFit truncates s to about the number of characters that fit width at
+// the table font size.
+func pdfFit(s string, width float64) string {
+	max := int(width/(pdfFontSize*0.55)) - 1
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-3]) + "..."
+	}
+	return s
+}
+
+// pdfString escapes s for a PDF literal string in WinAnsiEncoding.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
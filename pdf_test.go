@@ -0,0 +1,64 @@
+package cert
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertsPDF(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "example.com", Issuer: "Example CA", NotAfter: "2018-03-01 09:00:00 +0000 UTC", Status: StatusOK},
+		{DomainName: "down.example.com", Error: "connection refused (timeout)", Status: StatusError},
+	}
+	for i := 0; i < 40; i++ {
+		certs = append(certs, &Cert{DomainName: fmt.Sprintf("host%d.example.com", i), Status: StatusExpiring})
+	}
+	pdf := certs.PDF()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf(`unexpected document %q, want a PDF`, pdf)
+	}
+	for _, want := range []string{
+		"(Certificate inventory) Tj",
+		"(OK: 1, EXPIRING: 40, EXPIRED: 0, ERROR: 1) Tj",
+		"0 0.5 0 rg 600 465 Td (OK) Tj",
+		"(connection refused \\(timeout\\)) Tj",
+		"/Count 2",
+		"(Page 2 of 2) Tj",
+	} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf(`unexpected document without %q`, want)
+		}
+	}
+
+	// startxref points at the cross-reference table, whose entries point
+	// at the objects.
+	i := bytes.LastIndex(pdf, []byte("startxref\n"))
+	xref, _ := strconv.Atoi(strings.Fields(string(pdf[i+len("startxref\n"):]))[0])
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf(`unexpected startxref %d`, xref)
+	}
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	for n, line := range lines[3:] {
+		if line == "trailer" {
+			break
+		}
+		off, _ := strconv.Atoi(line[:10])
+		if want := fmt.Sprintf("%d 0 obj", n+1); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf(`unexpected offset %d of object %d`, off, n+1)
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	if got, want := pdfString(`a(b)\c äあ`), "a\\(b\\)\\\\c \xe4?"; got != want {
+		t.Errorf(`unexpected return value %q, want %q`, got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package cert
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Results of pinning in Cert.Pin.
+const (
+	PinPass = "PASS"
+	PinFail = "FAIL"
+)
+
+// LoadPins reads the pins of Options.Pins from a JSON object of host names
+// and their pins in the file at path.
+func LoadPins(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pins map[string][]string
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("Invalid pins %s: %v.", path, err)
+	}
+	for host, hostPins := range pins {
+		for _, pin := range hostPins {
+			if err := validatePin(pin); err != nil {
+				return nil, fmt.Errorf("Invalid pin %q of %s.", pin, host)
+			}
+		}
+	}
+	return pins, nil
+}
+
+// validatePin checks that pin is an SPKI pin such as "sha256/base64" or a
+// hex SHA-256 certificate fingerprint, with or without colons.
+func validatePin(pin string) error {
+	if spki, ok := strings.CutPrefix(pin, "sha256/"); ok {
+		if sum, err := base64.StdEncoding.DecodeString(spki); err != nil || len(sum) != sha256.Size {
+			return fmt.Errorf("Invalid SPKI pin %q.", pin)
+		}
+		return nil
+	}
+	if sum, err := hex.DecodeString(strings.ReplaceAll(pin, ":", "")); err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("Invalid fingerprint %q.", pin)
+	}
+	return nil
+}
+
+// checkPins returns PinPass if a certificate of chain matches one of pins,
+// by the SHA-256 of its SubjectPublicKeyInfo or of the whole certificate.
+func checkPins(chain []*x509.Certificate, pins []string) string {
+	for _, pin := range pins {
+		isSPKI := strings.HasPrefix(pin, "sha256/")
+		fingerprint := strings.ToLower(strings.ReplaceAll(pin, ":", ""))
+		for _, cert := range chain {
+			if isSPKI {
+				if spkiPin(cert.RawSubjectPublicKeyInfo) == pin {
+					return PinPass
+				}
+			} else if sum := sha256.Sum256(cert.Raw); hex.EncodeToString(sum[:]) == fingerprint {
+				return PinPass
+			}
+		}
+	}
+	return PinFail
+}
+
+// spkiPin returns the SPKI pin of a SubjectPublicKeyInfo, "sha256/" and
+// the base64 of its SHA-256.
+func spkiPin(spki []byte) string {
+	sum := sha256.Sum256(spki)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
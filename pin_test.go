@@ -0,0 +1,87 @@
+package cert
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckPins(t *testing.T) {
+	ca := newTestCA(t, "Test CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{DNSNames: []string{"example.com"}}, ca)
+	other := newTestCA(t, "Other CA", nil)
+	chain := []*x509.Certificate{leaf.cert, ca.cert}
+
+	spki := func(c *x509.Certificate) string {
+		sum := sha256.Sum256(c.RawSubjectPublicKeyInfo)
+		return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+	fingerprint := func(c *x509.Certificate) string {
+		sum := sha256.Sum256(c.Raw)
+		var parts []string
+		for _, b := range sum {
+			parts = append(parts, strings.ToUpper(hex.EncodeToString([]byte{b})))
+		}
+		return strings.Join(parts, ":")
+	}
+
+	tests := []struct {
+		pins []string
+		want string
+	}{
+		{[]string{spki(leaf.cert)}, PinPass},
+		{[]string{spki(other.cert), spki(ca.cert)}, PinPass},
+		{[]string{fingerprint(leaf.cert)}, PinPass},
+		{[]string{strings.ToLower(strings.ReplaceAll(fingerprint(ca.cert), ":", ""))}, PinPass},
+		{[]string{spki(other.cert), fingerprint(other.cert)}, PinFail},
+		{nil, PinFail},
+	}
+	for _, tt := range tests {
+		if got := checkPins(chain, tt.pins); got != tt.want {
+			t.Errorf(`unexpected result %s of pins %q, want %s`, got, tt.pins, tt.want)
+		}
+	}
+
+	stubChain(chain...)
+	defer stubCert()
+	opts := &Options{Pins: map[string][]string{"example.com": {spki(other.cert)}}}
+	certs, _ := NewCertsWithOptions([]string{"example.com", "www.example.com"}, opts)
+	if certs[0].Pin != PinFail || certs[1].Pin != "" {
+		t.Errorf(`unexpected pins %q and %q, want %s and none`, certs[0].Pin, certs[1].Pin, PinFail)
+	}
+	if fs := findings(certs[0]); len(fs) == 0 || fs[len(fs)-1].Rule != "pin-mismatch" {
+		t.Errorf(`unexpected findings %+v, want pin-mismatch`, fs)
+	}
+	if got := certs[0].String(); !strings.Contains(got, "\nPin:        FAIL\n") {
+		t.Errorf(`unexpected return value %q, want pin`, got)
+	}
+
+	targets := []Target{{Host: "example.com", Pins: []string{spki(ca.cert)}}}
+	if certs, _ := NewCertsFromTargets(targets, opts); certs[0].Pin != PinPass {
+		t.Errorf(`unexpected pin %q of target, want %s`, certs[0].Pin, PinPass)
+	}
+}
+
+func TestLoadPins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.json")
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(make([]byte, 32))
+	os.WriteFile(path, []byte(`{"example.com": ["`+pin+`"]}`), 0600)
+	pins, err := LoadPins(path)
+	if want := map[string][]string{"example.com": {pin}}; err != nil || !reflect.DeepEqual(pins, want) {
+		t.Errorf(`unexpected pins %q and err %v, want %q`, pins, err, want)
+	}
+
+	os.WriteFile(path, []byte(`{"example.com": ["sha256/short"]}`), 0600)
+	if _, err := LoadPins(path); err == nil || err.Error() != `Invalid pin "sha256/short" of example.com.` {
+		t.Errorf(`unexpected err %v, want invalid pin`, err)
+	}
+	if _, err := ParseTargets([]byte(`[{"host": "example.com", "pins": ["c0:ff:ee"]}]`)); err == nil || err.Error() != `Target 1: Invalid fingerprint "c0:ff:ee".` {
+		t.Errorf(`unexpected err %v, want invalid fingerprint`, err)
+	}
+}
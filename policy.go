@@ -0,0 +1,97 @@
+package cert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Policy overrides the severity of findings, e.g. to accept a known
+// deviation of a legacy appliance. Overridden findings are still reported,
+// with their new severity.
+type Policy struct {
+	// Severities maps rules, such as "weak-key" or the rule of a Checker,
+	// to "error", "warning" or "ignore".
+	Severities map[string]string `json:"severities,omitempty"`
+	// Exceptions override Severities for some domains.
+	Exceptions []PolicyException `json:"exceptions,omitempty"`
+}
+
+// PolicyException sets severities for one domain, or for its subdomains
+// if Domain is a pattern such as "*.example.com".
+type PolicyException struct {
+	Domain     string            `json:"domain"`
+	Severities map[string]string `json:"severities"`
+	// Reason documents why the exception was granted.
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadPolicy reads a Policy from the JSON file at path.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("Invalid policy %s: %v.", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *Policy) validate() error {
+	check := func(severities map[string]string) error {
+		for rule, severity := range severities {
+			switch severity {
+			case "error", "warning", "ignore":
+			default:
+				return fmt.Errorf("Unknown severity %q for %s.", severity, rule)
+			}
+		}
+		return nil
+	}
+	if err := check(p.Severities); err != nil {
+		return err
+	}
+	for i, e := range p.Exceptions {
+		if e.Domain == "" {
+			return fmt.Errorf("Exception %d has no domain.", i+1)
+		}
+		if err := check(e.Severities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// severities returns the severities p sets for the findings of domain.
+func (p *Policy) severities(domain string) map[string]string {
+	merged := map[string]string{}
+	for rule, severity := range p.Severities {
+		merged[rule] = severity
+	}
+	for _, e := range p.Exceptions {
+		if e.Domain == domain || strings.HasPrefix(e.Domain, "*.") && strings.HasSuffix(domain, e.Domain[1:]) {
+			for rule, severity := range e.Severities {
+				merged[rule] = severity
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// severity returns the severity of the built-in rule for c, the one
+// c.Policy sets or else the rule's default.
+func (c *Cert) severity(rule string) string {
+	if severity, ok := c.Policy[rule]; ok {
+		return severity
+	}
+	return ruleFinding(rule, "").Severity
+}
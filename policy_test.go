@@ -0,0 +1,78 @@
+package cert
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	os.WriteFile(path, []byte(`{
+		"severities": {"weak-key": "warning"},
+		"exceptions": [{"domain": "*.legacy.example.com", "severities": {"weak-key": "ignore"}, "reason": "Appliance."}]
+	}`), 0o644)
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if s := p.severities("www.example.com"); s["weak-key"] != "warning" {
+		t.Errorf(`unexpected severities %v, want weak-key warning`, s)
+	}
+	if s := p.severities("box.legacy.example.com"); s["weak-key"] != "ignore" {
+		t.Errorf(`unexpected severities %v, want weak-key ignore`, s)
+	}
+	if s := (&Policy{}).severities("example.com"); s != nil {
+		t.Errorf(`unexpected severities %v, want nil`, s)
+	}
+
+	for _, s := range []string{
+		`{"severities": {"weak-key": "critical"}}`,
+		`{"exceptions": [{"severities": {"weak-key": "ignore"}}]}`,
+		`{"exceptions": [{"domain": "a.example.com", "severities": {"weak-key": "off"}}]}`,
+		`severities: {}`,
+	} {
+		os.WriteFile(path, []byte(s), 0o644)
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf(`unexpected nil for %s, want error`, s)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	weak := &x509.Certificate{PublicKey: &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 1023), E: 65537}}
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return &serverInfo{chain: []*x509.Certificate{weak}}, nil
+	}
+	defer stubCert()
+
+	opts := &Options{Policy: &Policy{
+		Severities: map[string]string{"weak-key": "warning"},
+		Exceptions: []PolicyException{{Domain: "legacy.example.com", Severities: map[string]string{"weak-key": "ignore"}}},
+	}}
+	certs, _ := NewCertsWithOptions([]string{"www.example.com", "legacy.example.com"}, opts)
+
+	if ws := certs[0].Warnings; len(ws) != 1 || !strings.HasPrefix(ws[0], "RSA key has 1024 bits") {
+		t.Errorf(`unexpected warnings %q, want weak key`, ws)
+	}
+	if ws := certs[1].Warnings; len(ws) != 0 {
+		t.Errorf(`unexpected warnings %q, want none`, ws)
+	}
+	sarif := string(certs.SARIF())
+	if !strings.Contains(sarif, `"ruleId":"weak-key","level":"warning","message":{"text":"www.example.com: `) ||
+		!strings.Contains(sarif, `"ruleId":"weak-key","level":"none","message":{"text":"legacy.example.com: `) {
+		t.Errorf(`unexpected SARIF %s, want both findings with their severities`, sarif)
+	}
+	if cef := certs.CEF(); !strings.Contains(cef, "|weak-key|Certificate key is too small|0|dhost=legacy.example.com") {
+		t.Errorf(`unexpected CEF %q, want ignored finding with severity 0`, cef)
+	}
+	if got := string(certs.JSON()); !strings.Contains(got, `"policy":{"weak-key":"ignore"}`) {
+		t.Errorf(`unexpected JSON %s, want policy`, got)
+	}
+}
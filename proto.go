@@ -0,0 +1,107 @@
+package cert
+
+import (
+	"sort"
+)
+
+// Proto returns certs encoded as the Certs message of cert.proto.
+func (certs Certs) Proto() []byte {
+	var b []byte
+	for _, c := range certs {
+		b = appendBytes(b, 1, c.proto())
+	}
+	return b
+}
+
+func (c *Cert) proto() []byte {
+	var b []byte
+	b = appendString(b, 1, c.DomainName)
+	b = appendString(b, 2, c.IP)
+	b = appendString(b, 3, c.Issuer)
+	b = appendString(b, 4, c.CommonName)
+	for _, san := range c.SANs {
+		b = appendBytes(b, 5, []byte(san))
+	}
+	b = appendString(b, 6, c.NotBefore)
+	b = appendString(b, 7, c.NotAfter)
+	b = appendString(b, 8, c.Error)
+	b = appendString(b, 9, c.Status)
+	b = appendInt(b, 10, int64(c.SANCount))
+	b = appendString(b, 11, c.ErrorKind)
+	b = appendInt(b, 12, int64(c.ChainSize))
+	b = appendInt(b, 13, int64(c.ConnectTime))
+	b = appendInt(b, 14, int64(c.DNSTime))
+	b = appendInt(b, 15, int64(c.TCPTime))
+	b = appendInt(b, 16, int64(c.HandshakeTime))
+	b = appendString(b, 17, c.KeyExchange)
+	if c.PostQuantum {
+		b = appendInt(b, 18, 1)
+	}
+	keys := make([]string, 0, len(c.Labels))
+	for k := range c.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = appendString(entry, 1, k)
+		entry = appendString(entry, 2, c.Labels[k])
+		b = appendBytes(b, 19, entry)
+	}
+	b = appendString(b, 20, c.Port)
+	b = appendString(b, 21, c.Input)
+	b = appendString(b, 22, c.Scheme)
+	for _, name := range c.PTR {
+		b = appendBytes(b, 23, []byte(name))
+	}
+	b = appendString(b, 24, c.ChainNotAfter)
+	if info := c.IPInfo; info != nil {
+		var m []byte
+		m = appendInt(m, 1, int64(info.ASN))
+		m = appendString(m, 2, info.Organization)
+		m = appendString(m, 3, info.Country)
+		b = appendBytes(b, 25, m)
+	}
+	return b
+}
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendString appends s as field, omitting the proto3 default "".
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendBytes(b, field, []byte(s))
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendInt appends v as field, omitting the proto3 default 0.
+func appendInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendTag(b, field, wireVarint)
+	return appendVarint(b, uint64(v))
+}
@@ -0,0 +1,41 @@
+package cert
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCertsProto(t *testing.T) {
+	certs := Certs{
+		{DomainName: "a.io", SANs: []string{"a.io", "b.io"}, SANCount: 2, ConnectTime: 300 * time.Millisecond, PostQuantum: true, Labels: map[string]string{"z": "1", "a": "2"}},
+		{DomainName: "c.io", Error: "x", IPInfo: &IPInfo{ASN: 1, Country: "JP"}},
+	}
+	first := []byte{
+		0x0a, 4, 'a', '.', 'i', 'o',
+		0x2a, 4, 'a', '.', 'i', 'o',
+		0x2a, 4, 'b', '.', 'i', 'o',
+		0x50, 2,
+		0x68, 0x80, 0xc6, 0x86, 0x8f, 0x01,
+		0x90, 0x01, 1,
+		0x9a, 0x01, 6, 0x0a, 1, 'a', 0x12, 1, '2',
+		0x9a, 0x01, 6, 0x0a, 1, 'z', 0x12, 1, '1',
+	}
+	second := []byte{
+		0x0a, 4, 'c', '.', 'i', 'o',
+		0x42, 1, 'x',
+		0xca, 0x01, 6, 0x08, 1, 0x1a, 2, 'J', 'P',
+	}
+	var want []byte
+	want = append(want, 0x0a, byte(len(first)))
+	want = append(want, first...)
+	want = append(want, 0x0a, byte(len(second)))
+	want = append(want, second...)
+
+	if got := certs.Proto(); !bytes.Equal(got, want) {
+		t.Errorf(`unexpected return value % x, want % x`, got, want)
+	}
+	if got := (Certs{}).Proto(); len(got) != 0 {
+		t.Errorf(`unexpected return value % x, want empty`, got)
+	}
+}
@@ -0,0 +1,26 @@
+package cert
+
+import (
+	"context"
+	"strings"
+)
+
+var lookupAddr = func(ctx context.Context, opts *Options, ip string) ([]string, error) {
+	return opts.resolver().LookupAddr(ctx, ip)
+}
+
+// lookupPTR returns the reverse DNS names of ip without the trailing dot,
+// or nil if there are none or the lookup fails.
+func lookupPTR(ctx context.Context, opts *Options, ip string) []string {
+	_, span := startSpan(ctx, opts, "cert.ptr", "ip", ip)
+	names, err := lookupAddr(ctx, opts, ip)
+	span.End(err)
+	if err != nil {
+		opts.logDebug("reverse lookup failed", "ip", ip, "err", err)
+		return nil
+	}
+	for i, name := range names {
+		names[i] = strings.TrimSuffix(name, ".")
+	}
+	return names
+}
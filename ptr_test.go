@@ -0,0 +1,34 @@
+package cert
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewCertPTR(t *testing.T) {
+	stubCert()
+	defer func(orig func(context.Context, *Options, string) ([]string, error)) { lookupAddr = orig }(lookupAddr)
+	lookupAddr = func(ctx context.Context, opts *Options, ip string) ([]string, error) {
+		if ip != "127.0.0.1" {
+			return nil, errors.New("unexpected IP " + ip)
+		}
+		return []string{"edge-1.cdn.example.net."}, nil
+	}
+
+	c := NewCertWithOptions("example.com", &Options{PTR: true})
+	if want := []string{"edge-1.cdn.example.net"}; !reflect.DeepEqual(c.PTR, want) {
+		t.Errorf(`unexpected PTR %v, want %v`, c.PTR, want)
+	}
+	if c := NewCertWithOptions("example.com", &Options{}); c.PTR != nil {
+		t.Errorf(`unexpected PTR %v, want nil`, c.PTR)
+	}
+
+	lookupAddr = func(ctx context.Context, opts *Options, ip string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	if c := NewCertWithOptions("example.com", &Options{PTR: true}); c.PTR != nil || c.Error != "" {
+		t.Errorf(`unexpected PTR %v and error %q, want nil and ""`, c.PTR, c.Error)
+	}
+}
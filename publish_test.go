@@ -0,0 +1,115 @@
+package cert
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeNATS accepts clients, records their CONNECT options and published
+// payloads, and rejects publishing to subject "denied".
+func fakeNATS(t *testing.T) (string, chan map[string]interface{}, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	connects := make(chan map[string]interface{}, 2)
+	msgs := make(chan string, 10)
+	serve := func(conn net.Conn) {
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			f := strings.Fields(line)
+			switch f[0] {
+			case "CONNECT":
+				var opts map[string]interface{}
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &opts)
+				connects <- opts
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "PUB":
+				n, _ := strconv.Atoi(f[2])
+				payload := make([]byte, n+2)
+				io.ReadFull(r, payload)
+				if f[1] == "denied" {
+					fmt.Fprint(conn, "-ERR 'Permissions Violation for Publish to denied'\r\n")
+					continue
+				}
+				msgs <- f[1] + " " + string(payload[:n])
+			}
+		}
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+	return l.Addr().String(), connects, msgs
+}
+
+func TestNATSPublisher(t *testing.T) {
+	addr, connects, msgs := fakeNATS(t)
+
+	var p Publisher
+	p, err := DialNATS("nats://alice:secret@"+addr, "cert.results")
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	defer p.Close()
+
+	opts := <-connects
+	if opts["user"] != "alice" || opts["pass"] != "secret" || opts["verbose"] != false {
+		t.Errorf(`unexpected CONNECT options %v`, opts)
+	}
+
+	if err := p.Publish(Certs{{DomainName: "a.example.com"}, {DomainName: "b.example.com"}}); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		msg := <-msgs
+		if !strings.HasPrefix(msg, `cert.results {"domainName":"`+domain+`"`) {
+			t.Errorf(`unexpected message %q for %s`, msg, domain)
+		}
+	}
+
+	p.(*NATSPublisher).Subject = "denied"
+	if err := p.Publish(Certs{{DomainName: "a.example.com"}}); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf(`unexpected err %v, want permissions violation`, err)
+	}
+	if n := p.(*NATSPublisher).r.Buffered(); n != 0 {
+		t.Errorf(`unexpected %d bytes left after -ERR, want PONG read`, n)
+	}
+
+	p.(*NATSPublisher).Subject = "cert.results"
+	p.(*NATSPublisher).conn.Close()
+	if err := p.Publish(Certs{{DomainName: "c.example.com"}}); err != nil {
+		t.Fatalf(`unexpected err %s after connection loss, want nil`, err.Error())
+	}
+	if opts := <-connects; opts["user"] != "alice" {
+		t.Errorf(`unexpected CONNECT options %v on reconnect`, opts)
+	}
+	if msg := <-msgs; !strings.HasPrefix(msg, `cert.results {"domainName":"c.example.com"`) {
+		t.Errorf(`unexpected message %q after reconnect`, msg)
+	}
+}
+
+func TestDialNATSError(t *testing.T) {
+	if _, err := DialNATS("http://localhost:4222", "cert"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
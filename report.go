@@ -0,0 +1,85 @@
+package cert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON representation of Cert. It is
+// incremented when fields are renamed, removed or change meaning; adding
+// fields does not change it.
+const SchemaVersion = 1
+
+// Report wraps the results of a scan with metadata for consumers of the
+// JSON output.
+type Report struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	Generator     string         `json:"generator,omitempty"`
+	GeneratedAt   string         `json:"generatedAt"`
+	Targets       int            `json:"targets"`
+	Statuses      map[string]int `json:"statuses"`
+	Certs         Certs          `json:"certs"`
+}
+
+// Report returns certs wrapped in a Report. generator names the producing
+// tool and version, e.g. "cert 1.0.0".
+func (certs Certs) Report(generator string) *Report {
+	statuses := map[string]int{}
+	for _, c := range certs {
+		statuses[c.Status]++
+	}
+	return &Report{
+		SchemaVersion: SchemaVersion,
+		Generator:     generator,
+		GeneratedAt:   now().Format(timeLayout),
+		Targets:       len(certs),
+		Statuses:      statuses,
+		Certs:         certs,
+	}
+}
+
+// JSON returns the report as JSON.
+func (r *Report) JSON() []byte {
+	data, err := json.Marshal(r)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// ParseJSON parses the output of Certs.JSON or Report.JSON back into Certs.
+// Certificate chains are not part of the JSON and are not restored.
+func ParseJSON(data []byte) (Certs, error) {
+	var certs Certs
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var r Report
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		if r.SchemaVersion > SchemaVersion {
+			return nil, fmt.Errorf("Unsupported schema version %d.", r.SchemaVersion)
+		}
+		certs = r.Certs
+	} else if err := json.Unmarshal(data, &certs); err != nil {
+		return nil, err
+	}
+	if err := restoreCerts(certs); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// restoreCerts restores what certs read from JSON need besides their
+// fields. It fails on null results, which no output contains.
+func restoreCerts(certs Certs) error {
+	for i, c := range certs {
+		if c == nil {
+			return fmt.Errorf("Invalid result %d: null.", i+1)
+		}
+		if t, err := parseTime(c.NotAfter); err == nil {
+			c.notAfter = t
+		}
+	}
+	return nil
+}
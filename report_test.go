@@ -0,0 +1,84 @@
+package cert
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCertsReport(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "ok.example.com", Status: StatusOK},
+		{DomainName: "ok2.example.com", Status: StatusOK},
+		{DomainName: "failed.example.com", Status: StatusError, Error: "connection refused"},
+	}
+	r := certs.Report("cert 1.0.0")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(r.JSON(), &got); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if got["schemaVersion"] != float64(SchemaVersion) {
+		t.Errorf(`unexpected schemaVersion %v, want %d`, got["schemaVersion"], SchemaVersion)
+	}
+	if got["generator"] != "cert 1.0.0" {
+		t.Errorf(`unexpected generator %v, want %q`, got["generator"], "cert 1.0.0")
+	}
+	if got["generatedAt"] != "2018-01-01 00:00:00 +0000 UTC" {
+		t.Errorf(`unexpected generatedAt %v`, got["generatedAt"])
+	}
+	if got["targets"] != float64(3) {
+		t.Errorf(`unexpected targets %v, want 3`, got["targets"])
+	}
+	statuses := got["statuses"].(map[string]interface{})
+	if statuses[StatusOK] != float64(2) || statuses[StatusError] != float64(1) {
+		t.Errorf(`unexpected statuses %v`, statuses)
+	}
+	if len(got["certs"].([]interface{})) != 3 {
+		t.Errorf(`unexpected certs %v`, got["certs"])
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	mustParse := func(s string) time.Time {
+		t, err := parseTime(s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	certs := Certs{
+		{DomainName: "example.com", SANs: []string{"example.com", "www.example.com"}, NotAfter: "2018-06-01 00:00:00 +0000 UTC", Status: StatusOK, ChainSize: 2, Labels: map[string]string{"team": "web"}},
+		{DomainName: "failed.example.com", SANs: []string{}, Status: StatusError, Error: "connection refused", ErrorKind: ErrorKindRefused},
+	}
+	want := Certs{
+		{DomainName: "example.com", SANs: []string{"example.com", "www.example.com"}, NotAfter: "2018-06-01 00:00:00 +0000 UTC", Status: StatusOK, ChainSize: 2, Labels: map[string]string{"team": "web"}, notAfter: mustParse("2018-06-01 00:00:00 +0000 UTC")},
+		certs[1],
+	}
+
+	for _, data := range [][]byte{certs.JSON(), certs.Report("").JSON()} {
+		got, err := ParseJSON(data)
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf(`unexpected certs %+v, want %+v`, got, want)
+		}
+	}
+
+	if _, err := ParseJSON([]byte(`{"schemaVersion":99,"certs":[]}`)); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	if _, err := ParseJSON([]byte(`not json`)); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+	for _, s := range []string{`[null]`, `{"certs":[{"domainName":"example.com"},null]}`} {
+		if _, err := ParseJSON([]byte(s)); err == nil {
+			t.Errorf(`unexpected nil for %s, want error`, s)
+		}
+	}
+}
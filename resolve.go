@@ -0,0 +1,131 @@
+package cert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resolution is what scanning a target would connect to.
+type Resolution struct {
+	Target string   `json:"target"`
+	Host   string   `json:"host"`
+	Port   string   `json:"port"`
+	Addrs  []string `json:"addrs"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Resolutions is a list of Resolution.
+type Resolutions []Resolution
+
+// Resolve checks that targets parse, have valid ports and resolve, without
+// connecting to them, e.g. to validate a large target list before a scan.
+func Resolve(targets []string, opts *Options) Resolutions {
+	opts = opts.orDefault()
+	limit := tokens
+	if opts.Concurrency > 0 {
+		limit = make(chan struct{}, opts.Concurrency)
+	}
+	rs := make(Resolutions, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			rs[i] = resolve(target, opts)
+			<-limit
+		}(i, target)
+	}
+	wg.Wait()
+	return rs
+}
+
+// ResolveTargets is Resolve for targets with their own ports.
+func ResolveTargets(targets []Target, opts *Options) Resolutions {
+	s := make([]string, len(targets))
+	for i, t := range targets {
+		hostport, err := t.hostport()
+		if err != nil {
+			hostport = t.Host
+		}
+		s[i] = hostport
+	}
+	return Resolve(s, opts)
+}
+
+func resolve(target string, opts *Options) Resolution {
+	r := Resolution{Target: target, Addrs: []string{}}
+	var err error
+	r.Host, r.Port, err = splitTarget(target)
+	if err == nil {
+		err = validatePort(r.Port)
+	}
+	if err == nil {
+		r.Host, err = toASCII(r.Host)
+	}
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	addrs, err := opts.resolver().LookupIPAddr(ctx, r.Host)
+	if err == nil && len(addrs) == 0 {
+		err = errNoAddresses
+	}
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	for _, a := range addrs {
+		r.Addrs = append(r.Addrs, a.String())
+	}
+	return r
+}
+
+func validatePort(port string) error {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("Invalid port %q.", port)
+	}
+	return nil
+}
+
+// Failed reports whether any target could not be resolved.
+func (rs Resolutions) Failed() bool {
+	for _, r := range rs {
+		if r.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs Resolutions) String() string {
+	var b strings.Builder
+	for _, r := range rs {
+		if r.Error != "" {
+			fmt.Fprintf(&b, "%s: %s\n", r.Target, r.Error)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s port %s\n", r.Target, strings.Join(r.Addrs, " "), r.Port)
+	}
+	return b.String()
+}
+
+// JSON returns the resolutions as JSON.
+func (rs Resolutions) JSON() []byte {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
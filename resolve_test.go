@@ -0,0 +1,51 @@
+package cert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	rs := Resolve([]string{"127.0.0.1:8443", "localhost:99999", "127.0.0.1:no-such-service", "ftp://example.com", "no-such-host.invalid"}, nil)
+	if len(rs) != 5 {
+		t.Fatalf(`unexpected %d resolutions, want 5`, len(rs))
+	}
+
+	if r := rs[0]; r.Error != "" || r.Host != "127.0.0.1" || r.Port != "8443" || len(r.Addrs) != 1 || r.Addrs[0] != "127.0.0.1" {
+		t.Errorf(`unexpected resolution %+v`, r)
+	}
+	for _, r := range rs[1:] {
+		if r.Error == "" {
+			t.Errorf(`unexpected resolution %+v, want error`, r)
+		}
+	}
+	if rs[1].Error != `Invalid port "99999".` {
+		t.Errorf(`unexpected error %q, want %q`, rs[1].Error, `Invalid port "99999".`)
+	}
+	if rs[2].Error != `Unknown service "no-such-service".` {
+		t.Errorf(`unexpected error %q, want %q`, rs[2].Error, `Unknown service "no-such-service".`)
+	}
+	if r := Resolve([]string{"127.0.0.1:https"}, nil)[0]; r.Error != "" || r.Port != "443" {
+		t.Errorf(`unexpected resolution %+v, want port 443`, r)
+	}
+	if !rs.Failed() || rs[:1].Failed() {
+		t.Errorf(`unexpected Failed`)
+	}
+
+	if got := rs[:2].String(); got != "127.0.0.1:8443: 127.0.0.1 port 8443\nlocalhost:99999: Invalid port \"99999\".\n" {
+		t.Errorf(`unexpected return value %q`, got)
+	}
+	if got := string(rs[:1].JSON()); !strings.HasPrefix(got, `[{"target":"127.0.0.1:8443","host":"127.0.0.1","port":"8443","addrs":["127.0.0.1"]}`) {
+		t.Errorf(`unexpected return value %q`, got)
+	}
+}
+
+func TestResolveTargets(t *testing.T) {
+	rs := ResolveTargets([]Target{{Host: "127.0.0.1", Port: "993"}, {Host: "ftp://example.com", Port: "21"}}, nil)
+	if rs[0].Error != "" || rs[0].Target != "127.0.0.1:993" || rs[0].Port != "993" {
+		t.Errorf(`unexpected resolution %+v`, rs[0])
+	}
+	if rs[1].Error == "" {
+		t.Errorf(`unexpected resolution %+v, want error`, rs[1])
+	}
+}
@@ -0,0 +1,129 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RevocationPolicy decides how an unavailable revocation source is treated.
+type RevocationPolicy int
+
+const (
+	// RevocationSoftFail records the failure in Cert.RevocationError only.
+	RevocationSoftFail RevocationPolicy = iota
+	// RevocationHardFail also makes the failure the Cert's Error.
+	RevocationHardFail
+)
+
+const (
+	RevocationGood    = "good"
+	RevocationRevoked = "revoked"
+	RevocationUnknown = "unknown"
+)
+
+const ErrorKindRevocation = "revocation"
+
+var (
+	errRevoked = errors.New("certificate has been revoked")
+	errNoCRL   = errors.New("no CRL distribution point")
+)
+
+const maxCRLSize = 32 << 20
+
+// fetchCRL downloads the CRL at url through the proxy, local address and
+// resolver of opts. The connection is not kept alive, as the transport is
+// not reused.
+var fetchCRL = func(ctx context.Context, url string, opts *Options) ([]byte, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             opts.httpProxy,
+			DialContext:       opts.dialContext,
+			DisableKeepAlives: true,
+		},
+		Timeout: opts.Timeout,
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxCRLSize))
+}
+
+// checkRevocation looks the leaf up in the CRLs it points to and records
+// the outcome on c according to opts.RevocationPolicy. A leaf without CRL
+// distribution point is unknown under either policy, as there is nothing
+// that could be unavailable.
+func checkRevocation(ctx context.Context, c *Cert, chain []*x509.Certificate, opts *Options) {
+	revoked, err := crlStatus(ctx, chain, opts)
+	switch {
+	case revoked:
+		c.Revocation = RevocationRevoked
+		c.Error = errRevoked.Error()
+		c.ErrorKind = ErrorKindRevocation
+		c.Status = StatusError
+	case err != nil:
+		c.Revocation = RevocationUnknown
+		c.RevocationError = err.Error()
+		if opts.RevocationPolicy == RevocationHardFail && err != errNoCRL {
+			c.Error = err.Error()
+			c.ErrorKind = ErrorKindRevocation
+			c.Status = StatusError
+		}
+	default:
+		c.Revocation = RevocationGood
+	}
+}
+
+func crlStatus(ctx context.Context, chain []*x509.Certificate, opts *Options) (bool, error) {
+	leaf := chain[0]
+	if len(leaf.CRLDistributionPoints) == 0 {
+		return false, errNoCRL
+	}
+	var issuer *x509.Certificate
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+
+	var lastErr error
+	for _, url := range leaf.CRLDistributionPoints {
+		der, err := fetchCRL(ctx, url, opts)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		crl, err := x509.ParseRevocationList(der)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if issuer != nil {
+			if err := crl.CheckSignatureFrom(issuer); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+		if !crl.NextUpdate.IsZero() && now().After(crl.NextUpdate) {
+			lastErr = fmt.Errorf("CRL %s is stale", url)
+			continue
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, lastErr
+}
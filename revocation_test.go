@@ -0,0 +1,69 @@
+package cert
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestNewCertWithRevocation(t *testing.T) {
+	ca := newTestCA(t, "CA for test", nil)
+	good := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "good.example.com"}, CRLDistributionPoints: []string{"http://crl.example.com/ca.crl"}}, ca)
+	revoked := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "revoked.example.com"}, CRLDistributionPoints: []string{"http://crl.example.com/ca.crl"}}, ca)
+	noCRL := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "nocrl.example.com"}}, ca)
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: revoked.cert.SerialNumber, RevocationTime: time.Now()},
+		},
+	}, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(f func(context.Context, string, *Options) ([]byte, error)) { fetchCRL = f }(fetchCRL)
+	fetchCRL = func(ctx context.Context, url string, opts *Options) ([]byte, error) { return crl, nil }
+	defer stubCert()
+
+	var tests = []struct {
+		leaf       *x509.Certificate
+		policy     RevocationPolicy
+		revocation string
+		status     string
+	}{
+		{good.cert, RevocationSoftFail, RevocationGood, StatusOK},
+		{revoked.cert, RevocationSoftFail, RevocationRevoked, StatusError},
+		{noCRL.cert, RevocationSoftFail, RevocationUnknown, StatusOK},
+		{noCRL.cert, RevocationHardFail, RevocationUnknown, StatusOK},
+	}
+	for i, test := range tests {
+		stubChain(test.leaf, ca.cert)
+		c := NewCertWithOptions("example.com", &Options{CheckRevocation: true, RevocationPolicy: test.policy})
+		if c.Revocation != test.revocation {
+			t.Errorf(`#%d unexpected Cert.Revocation %q, want %q`, i, c.Revocation, test.revocation)
+		}
+		if c.Status != test.status {
+			t.Errorf(`#%d unexpected Cert.Status %q, want %q`, i, c.Status, test.status)
+		}
+	}
+
+	fetchCRL = func(ctx context.Context, url string, opts *Options) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	stubChain(good.cert, ca.cert)
+	c := NewCertWithOptions("example.com", &Options{CheckRevocation: true})
+	if c.RevocationError != "connection refused" || c.Error != "" {
+		t.Errorf(`unexpected soft-fail result %q, %q`, c.RevocationError, c.Error)
+	}
+	c = NewCertWithOptions("example.com", &Options{CheckRevocation: true, RevocationPolicy: RevocationHardFail})
+	if c.Error != "connection refused" || c.ErrorKind != ErrorKindRevocation {
+		t.Errorf(`unexpected hard-fail result %q, %q`, c.Error, c.ErrorKind)
+	}
+}
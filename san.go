@@ -0,0 +1,98 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SANRow is one SAN of a certificate with the fields identifying it.
+type SANRow struct {
+	DomainName string `json:"domainName"`
+	SAN        string `json:"san"`
+	NotAfter   string `json:"notAfter"`
+	Issuer     string `json:"issuer"`
+}
+
+// SANRows is a list of SANRow.
+type SANRows []SANRow
+
+// SANRows flattens certs into one row per SAN. Certs without SANs, such as
+// failed ones, yield a single row with an empty SAN.
+func (certs Certs) SANRows() SANRows {
+	rows := SANRows{}
+	for _, c := range certs {
+		sans := c.SANs
+		if len(sans) == 0 {
+			sans = []string{""}
+		}
+		for _, san := range sans {
+			rows = append(rows, SANRow{
+				DomainName: c.DomainName,
+				SAN:        san,
+				NotAfter:   c.NotAfter,
+				Issuer:     c.Issuer,
+			})
+		}
+	}
+	return rows
+}
+
+// CSV returns the rows as CSV with a header line.
+func (rows SANRows) CSV() string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write([]string{"DomainName", "SAN", "NotAfter", "Issuer"})
+	for _, r := range rows {
+		w.Write([]string{r.DomainName, r.SAN, r.NotAfter, r.Issuer})
+	}
+	w.Flush()
+	return b.String()
+}
+
+// JSON returns the rows as JSON.
+func (rows SANRows) JSON() []byte {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// sanIssues describes the malformed and duplicate SANs of cert, which hint
+// at bugs in generating its CSR: DNS names with spaces, underscores or a
+// trailing dot, IP addresses encoded as DNS names, and names listed twice.
+func sanIssues(cert *x509.Certificate) []string {
+	var issues []string
+	seen := map[string]bool{}
+	for _, name := range cert.DNSNames {
+		key := strings.ToLower(name)
+		if seen[key] {
+			issues = append(issues, fmt.Sprintf("SAN %q is listed more than once.", name))
+			continue
+		}
+		seen[key] = true
+		switch {
+		case net.ParseIP(name) != nil:
+			issues = append(issues, fmt.Sprintf("SAN %q is an IP address encoded as a DNS name.", name))
+		case strings.ContainsAny(name, " \t"):
+			issues = append(issues, fmt.Sprintf("SAN %q contains a space.", name))
+		case strings.Contains(name, "_"):
+			issues = append(issues, fmt.Sprintf("SAN %q contains an underscore.", name))
+		case strings.HasSuffix(name, "."):
+			issues = append(issues, fmt.Sprintf("SAN %q ends with a dot.", name))
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		key := "ip:" + ip.String()
+		if seen[key] {
+			issues = append(issues, fmt.Sprintf("SAN %s is listed more than once.", ip))
+		}
+		seen[key] = true
+	}
+	return issues
+}
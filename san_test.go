@@ -0,0 +1,63 @@
+package cert
+
+import (
+	"crypto/x509"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestCertsSANRows(t *testing.T) {
+	certs := Certs{
+		{DomainName: "example.com", SANs: []string{"example.com", "www.example.com"}, NotAfter: "2018-06-01 00:00:00 +0000 UTC", Issuer: "Example CA"},
+		{DomainName: "failed.example.com", SANs: []string{}, Error: "connection refused"},
+	}
+	want := SANRows{
+		{DomainName: "example.com", SAN: "example.com", NotAfter: "2018-06-01 00:00:00 +0000 UTC", Issuer: "Example CA"},
+		{DomainName: "example.com", SAN: "www.example.com", NotAfter: "2018-06-01 00:00:00 +0000 UTC", Issuer: "Example CA"},
+		{DomainName: "failed.example.com"},
+	}
+	rows := certs.SANRows()
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf(`unexpected rows %+v, want %+v`, rows, want)
+	}
+
+	expected := `DomainName,SAN,NotAfter,Issuer
+example.com,example.com,2018-06-01 00:00:00 +0000 UTC,Example CA
+example.com,www.example.com,2018-06-01 00:00:00 +0000 UTC,Example CA
+failed.example.com,,,
+`
+	if rows.CSV() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, rows.CSV(), expected)
+	}
+
+	expectedJSON := `[{"domainName":"example.com","san":"example.com","notAfter":"2018-06-01 00:00:00 +0000 UTC","issuer":"Example CA"},{"domainName":"example.com","san":"www.example.com","notAfter":"2018-06-01 00:00:00 +0000 UTC","issuer":"Example CA"},{"domainName":"failed.example.com","san":"","notAfter":"","issuer":""}]`
+	if string(rows.JSON()) != expectedJSON {
+		t.Errorf(`unexpected return value %q, want %q`, rows.JSON(), expectedJSON)
+	}
+	if string(Certs{}.SANRows().JSON()) != `[]` {
+		t.Errorf(`unexpected return value %q, want %q`, Certs{}.SANRows().JSON(), `[]`)
+	}
+}
+
+func TestSANIssues(t *testing.T) {
+	cert := &x509.Certificate{
+		DNSNames:    []string{"example.com", "WWW.example.com", "www.example.com", "my host.example.com", "_acme.example.com", "example.org.", "192.0.2.1"},
+		IPAddresses: []net.IP{net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 1)},
+	}
+	want := []string{
+		`SAN "www.example.com" is listed more than once.`,
+		`SAN "my host.example.com" contains a space.`,
+		`SAN "_acme.example.com" contains an underscore.`,
+		`SAN "example.org." ends with a dot.`,
+		`SAN "192.0.2.1" is an IP address encoded as a DNS name.`,
+		`SAN 192.0.2.1 is listed more than once.`,
+	}
+	if got := sanIssues(cert); !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected issues %q, want %q`, got, want)
+	}
+
+	if got := sanIssues(&x509.Certificate{DNSNames: []string{"example.com", "*.example.com"}}); got != nil {
+		t.Errorf(`unexpected issues %q, want nil`, got)
+	}
+}
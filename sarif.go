@@ -0,0 +1,327 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifRule describes a kind of finding.
+type sarifRule struct {
+	id, level, text string
+}
+
+var sarifRules = []sarifRule{
+	{"expired", "error", "Certificate has expired."},
+	{"expiring", "warning", "Certificate expires soon."},
+	{"revoked", "error", "Certificate has been revoked."},
+	{"tls-error", "error", "TLS handshake or certificate verification failed."},
+	{"unreachable", "error", "Host could not be reached."},
+	{"chain-issue", "warning", "Server sends a misconfigured certificate chain."},
+	{"weak-key", "error", "Certificate key is too small."},
+	{"weak-signature", "error", "Certificate is signed with a weak algorithm."},
+	{"weak-intermediate", "warning", "An intermediate certificate is signed with a weak algorithm."},
+	{"incomplete-chain", "warning", "Server sends no intermediate certificates."},
+	{"chain-expiry", "warning", "An intermediate certificate expires before the certificate."},
+	{"cn-only", "error", "Certificate names its host only in the CommonName, without subject alternative names."},
+	{"malformed-san", "warning", "A subject alternative name is malformed or listed twice."},
+	{"server-auth", "error", "Certificate is not valid for TLS server authentication."},
+	{"name-constraint", "error", "A name of the certificate violates the name constraints of an issuing CA."},
+	{"pin-mismatch", "error", "No certificate of the chain matches the pins of the host."},
+	{"key-mismatch", "error", "Certificate is not issued for the expected private key."},
+	{"csr-mismatch", "warning", "Certificate differs from the certificate signing request."},
+	{"sct", "warning", "An embedded SCT is invalid or from an unknown or retired log."},
+	{"no-downgrade-protection", "warning", "Server accepts downgraded handshakes despite TLS_FALLBACK_SCSV."},
+}
+
+// ruleFinding returns a Finding of the built-in rule with its default
+// severity.
+func ruleFinding(rule, message string) Finding {
+	for _, r := range sarifRules {
+		if r.id == rule {
+			return Finding{Rule: rule, Severity: r.level, Message: message}
+		}
+	}
+	return Finding{Rule: rule, Severity: "error", Message: message}
+}
+
+// findings returns the problems of c, those of the built-in rules followed
+// by c.Findings, with the severities of c.Policy.
+func findings(c *Cert) []Finding {
+	var fs []Finding
+	if rule := errorRule(c); rule != "" {
+		fs = append(fs, ruleFinding(rule, c.Error))
+	}
+	switch c.Status {
+	case StatusExpired:
+		fs = append(fs, ruleFinding("expired", fmt.Sprintf("Certificate expired at %s.", c.NotAfter)))
+	case StatusExpiring:
+		msg := fmt.Sprintf("Certificate expires at %s.", c.NotAfter)
+		if t, err := parseTime(c.NotAfter); err == nil {
+			msg = fmt.Sprintf("Certificate expires in %d days, at %s.", int(t.Sub(now()).Hours()/24), c.NotAfter)
+		}
+		fs = append(fs, ruleFinding("expiring", msg))
+	}
+	for _, issue := range c.ChainIssues {
+		fs = append(fs, ruleFinding("chain-issue", issue))
+	}
+	if len(c.chain) > 0 {
+		leaf := c.chain[0]
+		if msg := weakKey(leaf); msg != "" {
+			fs = append(fs, ruleFinding("weak-key", msg))
+		}
+		if weakSignature(leaf) {
+			fs = append(fs, ruleFinding("weak-signature", fmt.Sprintf("Certificate is signed with %s.", leaf.SignatureAlgorithm)))
+		}
+		for _, cert := range c.chain[1:] {
+			if weakSignature(cert) && !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+				fs = append(fs, ruleFinding("weak-intermediate", fmt.Sprintf("Intermediate %s is signed with %s.", cert.Subject.CommonName, cert.SignatureAlgorithm)))
+			}
+		}
+		for _, cert := range c.chain[1:] {
+			if cert.NotAfter.Before(leaf.NotAfter) && !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+				fs = append(fs, ruleFinding("chain-expiry", fmt.Sprintf("Intermediate %s expires at %s, before the certificate.", cert.Subject.CommonName, cert.NotAfter.In(time.Local))))
+			}
+		}
+		if len(c.chain) == 1 && !bytes.Equal(leaf.RawIssuer, leaf.RawSubject) {
+			fs = append(fs, ruleFinding("incomplete-chain", "Chain is incomplete: no intermediate certificates sent."))
+		}
+		if len(leaf.DNSNames) == 0 && len(leaf.IPAddresses) == 0 && len(leaf.EmailAddresses) == 0 && len(leaf.URIs) == 0 {
+			fs = append(fs, ruleFinding("cn-only", fmt.Sprintf("Certificate has no subject alternative names, only CommonName %q.", leaf.Subject.CommonName)))
+		}
+		for _, msg := range sanIssues(leaf) {
+			fs = append(fs, ruleFinding("malformed-san", msg))
+		}
+		for _, msg := range serverAuthIssues(c.chain) {
+			fs = append(fs, ruleFinding("server-auth", msg))
+		}
+		for _, msg := range nameConstraintViolations(c.chain) {
+			fs = append(fs, ruleFinding("name-constraint", msg))
+		}
+	}
+	if c.Pin == PinFail {
+		fs = append(fs, ruleFinding("pin-mismatch", fmt.Sprintf("No certificate of the chain matches the pins of %s.", c.DomainName)))
+	}
+	if c.KeyMatch != nil && !*c.KeyMatch {
+		fs = append(fs, ruleFinding("key-mismatch", "Certificate does not match the private key."))
+	}
+	for _, d := range c.CSRDiffs {
+		fs = append(fs, ruleFinding("csr-mismatch", fmt.Sprintf("%s is %q, requested %q.", d.Field, d.Cert, d.CSR)))
+	}
+	for _, sct := range c.SCTs {
+		if sct.Status != "" && sct.Status != SCTValid {
+			log := sct.Log
+			if log == "" {
+				log = sct.LogID
+			}
+			fs = append(fs, ruleFinding("sct", fmt.Sprintf("SCT of log %s from %s is %s.", log, sct.Timestamp.Format("2006-01-02"), sct.Status)))
+		}
+	}
+	if c.DowngradeProtection == DowngradeUnprotected {
+		fs = append(fs, ruleFinding("no-downgrade-protection", "Server accepted a downgraded handshake with TLS_FALLBACK_SCSV."))
+	}
+	fs = append(fs, c.Findings...)
+	for i, f := range fs {
+		if severity, ok := c.Policy[f.Rule]; ok {
+			fs[i].Severity = severity
+		}
+	}
+	return fs
+}
+
+// errorRule returns the rule of the error of c, or "" if c has none.
+func errorRule(c *Cert) string {
+	switch {
+	case c.ErrorKind == ErrorKindRevocation && c.Revocation == RevocationRevoked:
+		return "revoked"
+	case c.ErrorKind == ErrorKindTLS:
+		return "tls-error"
+	case c.Error != "":
+		return "unreachable"
+	}
+	return ""
+}
+
+// weakSignature reports whether cert is signed with MD2, MD5 or SHA-1.
+func weakSignature(cert *x509.Certificate) bool {
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		return true
+	}
+	return false
+}
+
+// warnings returns the messages of the findings of c of severity warning.
+func warnings(c *Cert) []string {
+	var ws []string
+	for _, f := range findings(c) {
+		if f.Severity == "warning" {
+			ws = append(ws, f.Message)
+		}
+	}
+	return ws
+}
+
+// weakKey describes why the public key of cert is too small, or returns "".
+func weakKey(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < 2048 {
+			return fmt.Sprintf("RSA key has %d bits, want at least 2048.", bits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := key.Curve.Params().BitSize; bits < 256 {
+			return fmt.Sprintf("ECDSA key has %d bits, want at least 256.", bits)
+		}
+	}
+	return ""
+}
+
+// extKeyUsageNames are the names of the extended key usages in findings.
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:             "any",
+	x509.ExtKeyUsageServerAuth:      "serverAuth",
+	x509.ExtKeyUsageClientAuth:      "clientAuth",
+	x509.ExtKeyUsageCodeSigning:     "codeSigning",
+	x509.ExtKeyUsageEmailProtection: "emailProtection",
+	x509.ExtKeyUsageTimeStamping:    "timeStamping",
+	x509.ExtKeyUsageOCSPSigning:     "OCSPSigning",
+}
+
+// serverAuthIssues describes why the certificates of chain are not valid
+// for TLS server authentication: a leaf without extended key usage, which
+// the Baseline Requirements and Apple platforms require, or a certificate
+// whose extended key usage does not include serverAuth.
+func serverAuthIssues(chain []*x509.Certificate) []string {
+	var issues []string
+	for i, cert := range chain {
+		if len(cert.ExtKeyUsage) == 0 && len(cert.UnknownExtKeyUsage) == 0 {
+			if i == 0 {
+				issues = append(issues, "Certificate has no extended key usage, want serverAuth.")
+			}
+			continue
+		}
+		var names []string
+		ok := false
+		for _, usage := range cert.ExtKeyUsage {
+			ok = ok || usage == x509.ExtKeyUsageServerAuth || usage == x509.ExtKeyUsageAny
+			name, known := extKeyUsageNames[usage]
+			if !known {
+				name = fmt.Sprintf("%d", usage)
+			}
+			names = append(names, name)
+		}
+		for _, oid := range cert.UnknownExtKeyUsage {
+			names = append(names, oid.String())
+		}
+		if ok {
+			continue
+		}
+		if i == 0 {
+			issues = append(issues, fmt.Sprintf("Certificate extended key usage %s does not include serverAuth.", strings.Join(names, ", ")))
+		} else {
+			issues = append(issues, fmt.Sprintf("Intermediate %s extended key usage %s does not include serverAuth.", cert.Subject.CommonName, strings.Join(names, ", ")))
+		}
+	}
+	return issues
+}
+
+// SARIF returns the findings of certs as a SARIF 2.1.0 log, e.g. for GitHub
+// code scanning. Each finding is located at the host it was found on.
+func (certs Certs) SARIF() []byte {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID                   string  `json:"id"`
+		ShortDescription     message `json:"shortDescription"`
+		DefaultConfiguration struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	rules := make([]rule, len(sarifRules))
+	known := map[string]bool{}
+	for i, r := range sarifRules {
+		rules[i].ID = r.id
+		rules[i].ShortDescription.Text = r.text
+		rules[i].DefaultConfiguration.Level = r.level
+		known[r.id] = true
+	}
+
+	results := []result{}
+	for _, c := range certs {
+		var loc location
+		loc.PhysicalLocation.ArtifactLocation.URI = hostport(c)
+		for _, f := range findings(c) {
+			if !known[f.Rule] {
+				// Rules of custom checkers.
+				var r rule
+				r.ID = f.Rule
+				r.ShortDescription.Text = f.Rule
+				r.DefaultConfiguration.Level = f.Severity
+				rules = append(rules, r)
+				known[f.Rule] = true
+			}
+			text := f.Message
+			if !strings.HasSuffix(text, ".") {
+				text += "."
+			}
+			level := f.Severity
+			if level == "ignore" {
+				level = "none"
+			}
+			results = append(results, result{
+				RuleID:    f.Rule,
+				Level:     level,
+				Message:   message{fmt.Sprintf("%s: %s", hostport(c), text)},
+				Locations: []location{loc},
+			})
+		}
+	}
+
+	log := map[string]interface{}{
+		"$schema": sarifSchema,
+		"version": sarifVersion,
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "cert",
+						"informationUri": "https://github.com/genkiroid/cert",
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+	data, err := json.Marshal(log)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
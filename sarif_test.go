@@ -0,0 +1,182 @@
+package cert
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertsSARIF(t *testing.T) {
+	weak := &x509.Certificate{
+		PublicKey:          &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 1023), E: 65537},
+		SignatureAlgorithm: x509.SHA1WithRSA,
+		ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:           []string{"weak.example.com"},
+	}
+	certs := Certs{
+		{DomainName: "ok.example.com", Status: StatusOK},
+		{DomainName: "expired.example.com", Status: StatusExpired, NotAfter: "2017-06-01 00:00:00 +0000 UTC"},
+		{DomainName: "expiring.example.com", Status: StatusExpiring, ChainIssues: []string{"Chain is out of order."}},
+		{DomainName: "revoked.example.com", Status: StatusError, Error: "Certificate is revoked.", ErrorKind: ErrorKindRevocation, Revocation: RevocationRevoked},
+		{DomainName: "untrusted.example.com", Status: StatusError, Error: "x509: certificate signed by unknown authority", ErrorKind: ErrorKindTLS},
+		{DomainName: "down.example.com", Status: StatusError, Error: "connection refused", ErrorKind: ErrorKindRefused},
+		{DomainName: "weak.example.com", Status: StatusOK, chain: []*x509.Certificate{weak}},
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(certs.SARIF(), &log); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf(`unexpected version %q, want %q`, log.Version, "2.1.0")
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != len(sarifRules) {
+		t.Fatalf(`unexpected runs %+v`, log.Runs)
+	}
+
+	var got []string
+	for _, r := range log.Runs[0].Results {
+		got = append(got, r.Locations[0].PhysicalLocation.ArtifactLocation.URI+" "+r.RuleID+" "+r.Level)
+	}
+	want := []string{
+		"expired.example.com expired error",
+		"expiring.example.com expiring warning",
+		"expiring.example.com chain-issue warning",
+		"revoked.example.com revoked error",
+		"untrusted.example.com tls-error error",
+		"down.example.com unreachable error",
+		"weak.example.com weak-key error",
+		"weak.example.com weak-signature error",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected results %q, want %q`, got, want)
+	}
+}
+
+func TestServerAuthIssues(t *testing.T) {
+	serverAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	clientAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageEmailProtection}
+	tests := []struct {
+		chain []*x509.Certificate
+		want  []string
+	}{
+		{[]*x509.Certificate{{ExtKeyUsage: serverAuth}, {}}, nil},
+		{[]*x509.Certificate{{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}}, nil},
+		{[]*x509.Certificate{{}}, []string{"Certificate has no extended key usage, want serverAuth."}},
+		{[]*x509.Certificate{{ExtKeyUsage: clientAuth}}, []string{"Certificate extended key usage clientAuth, emailProtection does not include serverAuth."}},
+		{
+			[]*x509.Certificate{{ExtKeyUsage: serverAuth}, {Subject: pkix.Name{CommonName: "Client CA"}, ExtKeyUsage: clientAuth}},
+			[]string{"Intermediate Client CA extended key usage clientAuth, emailProtection does not include serverAuth."},
+		},
+	}
+	for _, tt := range tests {
+		if got := serverAuthIssues(tt.chain); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf(`unexpected issues %q, want %q`, got, tt.want)
+		}
+	}
+
+	c := &Cert{chain: []*x509.Certificate{{ExtKeyUsage: clientAuth, DNSNames: []string{"example.com"}}}}
+	if fs := findings(c); len(fs) != 1 || fs[0].Rule != "server-auth" || fs[0].Severity != "error" {
+		t.Errorf(`unexpected findings %+v, want server-auth error`, fs)
+	}
+}
+
+func TestCNOnlyFinding(t *testing.T) {
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "legacy.example.com"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}
+	c := &Cert{chain: []*x509.Certificate{leaf}}
+	want := []Finding{{Rule: "cn-only", Severity: "error", Message: `Certificate has no subject alternative names, only CommonName "legacy.example.com".`}}
+	if fs := findings(c); !reflect.DeepEqual(fs, want) {
+		t.Errorf(`unexpected findings %+v, want %+v`, fs, want)
+	}
+
+	leaf.IPAddresses = []net.IP{net.IPv4(192, 0, 2, 1)}
+	if fs := findings(c); len(fs) != 0 {
+		t.Errorf(`unexpected findings %+v, want none`, fs)
+	}
+}
+
+func TestCertsSARIFNoFindings(t *testing.T) {
+	data := Certs{{DomainName: "ok.example.com", Status: StatusOK}}.SARIF()
+	var log map[string]interface{}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	results := log["runs"].([]interface{})[0].(map[string]interface{})["results"]
+	if results == nil || len(results.([]interface{})) != 0 {
+		t.Errorf(`unexpected results %v, want []`, results)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	ca := newTestCA(t, "Root CA", nil)
+	inter := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "SHA-1 Intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, ca)
+	inter.cert.SignatureAlgorithm = x509.SHA1WithRSA
+	leaf := newTestCert(t, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		NotBefore: time.Now().Add(-time.Hour),
+		NotAfter:  time.Now().Add(12*24*time.Hour + time.Hour),
+	}, inter)
+
+	chain := []*x509.Certificate{leaf.cert, inter.cert}
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		return &serverInfo{chain: chain}, nil
+	}
+	defer stubCert()
+
+	c := NewCert("example.com")
+	want := []string{
+		"Certificate expires in 12 days, at " + c.NotAfter + ".",
+		"Intermediate SHA-1 Intermediate is signed with SHA1-RSA.",
+	}
+	if c.Status != StatusExpiring || c.Error != "" || !reflect.DeepEqual(c.Warnings, want) {
+		t.Errorf(`unexpected status %s, error %q and warnings %q, want %q`, c.Status, c.Error, c.Warnings, want)
+	}
+	if got := c.String(); !strings.Contains(got, "\nWarnings:   Certificate expires in 12 days") {
+		t.Errorf(`unexpected return value %q, want warnings`, got)
+	}
+
+	chain = chain[:1]
+	if c := NewCert("example.com"); len(c.Warnings) != 2 || c.Warnings[1] != "Chain is incomplete: no intermediate certificates sent." {
+		t.Errorf(`unexpected warnings %q, want incomplete chain`, c.Warnings)
+	}
+
+	stubCert()
+	if c := NewCert("example.com"); c.Warnings != nil || strings.Contains(c.String(), "Warnings") {
+		t.Errorf(`unexpected warnings %q, want none`, c.Warnings)
+	}
+}
@@ -0,0 +1,97 @@
+package cert
+
+import "context"
+
+// Scanner fetches certificates with a configuration of its own. Unlike
+// NewCertsWithOptions it does not share the package level concurrency
+// limit, so services can keep one Scanner per tenant, each with its own
+// timeout, concurrency, resolver, proxy and TLS options, and scan with
+// them at the same time. Set Options.ExpiringThreshold, Debug and Logger
+// rather than the package level variables of the same names. Locale
+// remains global and applies to the output of every Scanner.
+type Scanner struct {
+	Options
+}
+
+// NewScanner returns a Scanner with a copy of opts, or DefaultOptions if
+// opts is nil.
+func NewScanner(opts *Options) *Scanner {
+	return &Scanner{Options: *opts.orDefault()}
+}
+
+// Scan fetches the certificates of targets, given as for
+// NewCertsWithOptions, until ctx is done. Zero Concurrency scans at most
+// 128 targets at once.
+func (s *Scanner) Scan(ctx context.Context, targets []string) (Certs, error) {
+	if err := validate(targets); err != nil {
+		return nil, err
+	}
+	return scanStrings(ctx, targets, s.options())
+}
+
+// ScanTargets is Scan for targets with their own settings, as for
+// NewCertsFromTargets.
+func (s *Scanner) ScanTargets(ctx context.Context, targets []Target) (Certs, error) {
+	return scanTargets(ctx, targets, s.options())
+}
+
+func (s *Scanner) options() *Options {
+	opts := s.Options
+	if opts.Concurrency == 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	return &opts
+}
+
+// ScanStream fetches the certificates of the targets received from
+// targets until it is closed and calls fn with each result in the order of
+// targets. Only the results of targets in flight are held, so lists of any
+// length scan in bounded memory. Unlike Scan it neither deduplicates
+// targets nor follows redirects. It stops when ctx is done, returning
+// ctx.Err(), when fn returns an error, returning it, or when FailFast trips,
+// returning an *AbortError.
+func (s *Scanner) ScanStream(ctx context.Context, targets <-chan string, fn func(c *Cert) error) error {
+	opts := s.options()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan func() *Cert)
+	go func() {
+		defer close(jobs)
+		for {
+			var target string
+			var ok bool
+			select {
+			case target, ok = <-targets:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			job := func() *Cert { return newCertContext(ctx, target, opts) }
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var err error
+	scanOrdered(opts.Concurrency, jobs, func(c *Cert) {
+		if err != nil {
+			return
+		}
+		if err = fn(c); err == nil && opts.FailFast.trips(c) {
+			err = &AbortError{Cert: c}
+		}
+		if err != nil {
+			cancel()
+		}
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package cert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScannerExpiringThreshold(t *testing.T) {
+	stubCert()
+	now = func() time.Time { return time.Date(2017, time.December, 1, 0, 0, 0, 0, time.Local) }
+	defer func() { now = time.Now }()
+
+	strict := NewScanner(&Options{ExpiringThreshold: 60 * 24 * time.Hour})
+	lax := NewScanner(&Options{ExpiringThreshold: 7 * 24 * time.Hour})
+	for _, test := range []struct {
+		scanner *Scanner
+		want    string
+	}{
+		{strict, StatusExpiring},
+		{lax, StatusOK},
+	} {
+		certs, err := test.scanner.Scan(context.Background(), []string{"example.com"})
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if certs[0].Status != test.want {
+			t.Errorf(`unexpected status %q, want %q`, certs[0].Status, test.want)
+		}
+	}
+}
+
+func TestScannerResolver(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	s := NewScanner(&Options{Resolver: &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("no DNS for tenant")
+		},
+	}})
+	certs, err := s.Scan(context.Background(), []string{"example.test"})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if !strings.Contains(certs[0].Error, "no DNS for tenant") {
+		t.Errorf(`unexpected error %q, want the resolver's`, certs[0].Error)
+	}
+}
+
+func TestScannerCanceled(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	host, port := startTLSServer(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	certs, err := NewScanner(nil).ScanTargets(ctx, []Target{{Host: host, Port: port}})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if certs[0].Error == "" {
+		t.Error(`unexpected empty error, want cancellation`)
+	}
+}
+
+func TestScannerOptions(t *testing.T) {
+	s := NewScanner(&Options{Timeout: time.Second})
+	if opts := s.options(); opts.Concurrency != defaultConcurrency || opts.Timeout != time.Second {
+		t.Errorf(`unexpected options %+v`, opts)
+	}
+	if s.Concurrency != 0 {
+		t.Errorf(`unexpected concurrency %d, want 0`, s.Concurrency)
+	}
+}
+
+func TestScannerScanStream(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		// Later targets finish first.
+		n, _ := strconv.Atoi(strings.TrimPrefix(host, "host"))
+		time.Sleep(time.Duration(20-n%20) * 100 * time.Microsecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	targets := make(chan string)
+	go func() {
+		for i := 0; i < 200; i++ {
+			targets <- fmt.Sprintf("host%d", i)
+		}
+		close(targets)
+	}()
+	var got []string
+	err := NewScanner(&Options{Concurrency: 3}).ScanStream(context.Background(), targets, func(c *Cert) error {
+		got = append(got, c.DomainName)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(got) != 200 {
+		t.Fatalf(`unexpected %d results, want 200`, len(got))
+	}
+	for i, name := range got {
+		if want := fmt.Sprintf("host%d", i); name != want {
+			t.Fatalf(`unexpected result %q at %d, want %q`, name, i, want)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf(`unexpected %d concurrent fetches, want at most 3`, maxRunning)
+	}
+}
+
+func TestScannerScanStreamStops(t *testing.T) {
+	stubCert()
+
+	targets := make(chan string)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case targets <- fmt.Sprintf("host%d", i):
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+	stop := errors.New("stop")
+	n := 0
+	err := NewScanner(nil).ScanStream(context.Background(), targets, func(c *Cert) error {
+		if n++; n == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 10 {
+		t.Errorf(`unexpected err %v after %d results, want %v after 10`, err, n, stop)
+	}
+}
@@ -0,0 +1,287 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	_ "embed"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// Results of verifying an SCT in SCT.Status.
+const (
+	SCTValid = "valid"
+	// SCTInvalid means the signature does not match the certificate.
+	SCTInvalid = "invalid"
+	// SCTUnknownLog means the log is not in the log list, or not yet
+	// trusted by it.
+	SCTUnknownLog = "unknown-log"
+	// SCTRetiredLog means the signature is valid but the log is retired or
+	// rejected, so clients may not count the SCT.
+	SCTRetiredLog = "retired-log"
+)
+
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// SCT is a signed certificate timestamp embedded in the leaf, a promise of
+// a Certificate Transparency log to publish it.
+type SCT struct {
+	// LogID is the base64 SHA-256 hash of the log's key, as in log lists.
+	LogID     string    `json:"logID"`
+	Log       string    `json:"log,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Status is the result of verifying the SCT against Options.CTLogs,
+	// or empty if not set.
+	Status string `json:"status,omitempty"`
+
+	hashAlg, sigAlg byte
+	extensions      []byte
+	signature       []byte
+}
+
+// CTLog is a Certificate Transparency log of a log list.
+type CTLog struct {
+	Description string
+	// State is the state of the log in the list, such as "usable" or
+	// "retired".
+	State string
+	Key   crypto.PublicKey
+}
+
+// CTLogs are the known logs by base64 log ID.
+type CTLogs map[string]CTLog
+
+//go:generate curl -sSfo ct_log_list.json https://www.gstatic.com/ct/log_list/v3/log_list.json
+
+// ctLogList is the snapshot of the log list that go generate downloads.
+//
+//go:embed ct_log_list.json
+var ctLogList []byte
+
+// embeddedCTLogs returns the logs of ctLogList, which SCTs are verified
+// against if Options.CTLogs is nil. An empty snapshot leaves them
+// unverified.
+var embeddedCTLogs = sync.OnceValue(func() CTLogs {
+	logs, err := parseCTLogs(ctLogList, "ct_log_list.json")
+	if err != nil || len(logs) == 0 {
+		return nil
+	}
+	return logs
+})
+
+// LoadCTLogs reads a log list in the v3 JSON format of Chrome, e.g. a copy
+// of https://www.gstatic.com/ct/log_list/v3/log_list.json.
+func LoadCTLogs(path string) (CTLogs, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCTLogs(data, path)
+}
+
+func parseCTLogs(data []byte, name string) (CTLogs, error) {
+	var list struct {
+		Operators []struct {
+			Logs []struct {
+				Description string                     `json:"description"`
+				LogID       string                     `json:"log_id"`
+				Key         []byte                     `json:"key"`
+				State       map[string]json.RawMessage `json:"state"`
+			} `json:"logs"`
+		} `json:"operators"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("Invalid log list %s: %v.", name, err)
+	}
+	logs := CTLogs{}
+	for _, op := range list.Operators {
+		for _, l := range op.Logs {
+			key, err := x509.ParsePKIXPublicKey(l.Key)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid key of log %s: %v.", l.Description, err)
+			}
+			log := CTLog{Description: l.Description, Key: key}
+			for state := range l.State {
+				log.State = state
+			}
+			logs[l.LogID] = log
+		}
+	}
+	return logs, nil
+}
+
+// scts parses the SCTs embedded in the leaf of chain and verifies them
+// against the leaf and its issuer with opts.CTLogs, or the embedded log
+// list if nil.
+func scts(chain []*x509.Certificate, opts *Options) []SCT {
+	leaf := chain[0]
+	var list []byte
+	for _, e := range leaf.Extensions {
+		if e.Id.Equal(oidSCTList) {
+			if _, err := asn1.Unmarshal(e.Value, &list); err != nil {
+				return nil
+			}
+		}
+	}
+	if list == nil {
+		return nil
+	}
+	scts, err := parseSCTList(list)
+	if err != nil {
+		opts.logDebug("invalid SCT list", "err", err)
+		return nil
+	}
+	logs := opts.CTLogs
+	if logs == nil {
+		logs = embeddedCTLogs()
+	}
+	if logs == nil {
+		return scts
+	}
+
+	var tbs []byte
+	var issuerKeyHash [32]byte
+	if len(chain) > 1 {
+		if tbs, err = removeSCTList(leaf.RawTBSCertificate); err != nil {
+			opts.logDebug("invalid TBSCertificate", "err", err)
+		}
+		issuerKeyHash = sha256.Sum256(chain[1].RawSubjectPublicKeyInfo)
+	}
+	for i := range scts {
+		sct := &scts[i]
+		log, ok := logs[sct.LogID]
+		if !ok || log.State == "pending" {
+			sct.Status = SCTUnknownLog
+			continue
+		}
+		sct.Log = log.Description
+		switch {
+		case tbs == nil:
+			// Without the issuer the signed data cannot be rebuilt.
+		case verifySCT(sct, log.Key, issuerKeyHash, tbs) != nil:
+			sct.Status = SCTInvalid
+		case log.State == "retired" || log.State == "rejected":
+			sct.Status = SCTRetiredLog
+		default:
+			sct.Status = SCTValid
+		}
+	}
+	return scts
+}
+
+// parseSCTList parses a SignedCertificateTimestampList of RFC 6962.
+func parseSCTList(b []byte) ([]SCT, error) {
+	errMalformed := errors.New("malformed SCT list")
+	vector := func(b []byte) ([]byte, []byte, error) {
+		if len(b) < 2 || len(b) < 2+int(binary.BigEndian.Uint16(b)) {
+			return nil, nil, errMalformed
+		}
+		n := 2 + int(binary.BigEndian.Uint16(b))
+		return b[2:n], b[n:], nil
+	}
+
+	list, rest, err := vector(b)
+	if err != nil || len(rest) > 0 {
+		return nil, errMalformed
+	}
+	var scts []SCT
+	for len(list) > 0 {
+		var raw []byte
+		if raw, list, err = vector(list); err != nil {
+			return nil, err
+		}
+		// version, log ID and timestamp
+		if len(raw) < 41 || raw[0] != 0 {
+			return nil, errMalformed
+		}
+		sct := SCT{
+			LogID:     base64.StdEncoding.EncodeToString(raw[1:33]),
+			Timestamp: time.UnixMilli(int64(binary.BigEndian.Uint64(raw[33:41]))).UTC(),
+		}
+		if sct.extensions, raw, err = vector(raw[41:]); err != nil || len(raw) < 2 {
+			return nil, errMalformed
+		}
+		sct.hashAlg, sct.sigAlg = raw[0], raw[1]
+		if sct.signature, raw, err = vector(raw[2:]); err != nil || len(raw) > 0 {
+			return nil, errMalformed
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// verifySCT verifies the signature of sct by key over a precertificate
+// entry of tbs, issued by the key hashing to issuerKeyHash.
+func verifySCT(sct *SCT, key crypto.PublicKey, issuerKeyHash [32]byte, tbs []byte) error {
+	if sct.hashAlg != 4 { // sha256
+		return fmt.Errorf("unsupported hash algorithm %d", sct.hashAlg)
+	}
+	var data []byte
+	data = append(data, 0, 0) // v1, certificate_timestamp
+	data = binary.BigEndian.AppendUint64(data, uint64(sct.Timestamp.UnixMilli()))
+	data = append(data, 0, 1) // precert_entry
+	data = append(data, issuerKeyHash[:]...)
+	data = append(data, byte(len(tbs)>>16), byte(len(tbs)>>8), byte(len(tbs)))
+	data = append(data, tbs...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(sct.extensions)))
+	data = append(data, sct.extensions...)
+	digest := sha256.Sum256(data)
+
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if sct.sigAlg == 3 && ecdsa.VerifyASN1(key, digest[:], sct.signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if sct.sigAlg == 1 {
+			return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sct.signature)
+		}
+	}
+	return errors.New("invalid SCT signature")
+}
+
+// tbsCertificate mirrors the TBSCertificate of RFC 5280 closely enough to
+// encode it again unchanged.
+type tbsCertificate struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           asn1.RawValue
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	IssuerUniqueID     asn1.BitString   `asn1:"optional,tag:1"`
+	SubjectUniqueID    asn1.BitString   `asn1:"optional,tag:2"`
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+// removeSCTList returns tbs without its SCT list extension, the
+// precertificate TBSCertificate the SCTs were issued for.
+func removeSCTList(tbs []byte) ([]byte, error) {
+	var t tbsCertificate
+	if rest, err := asn1.Unmarshal(tbs, &t); err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, errors.New("trailing data after TBSCertificate")
+	}
+	exts := t.Extensions[:0]
+	for _, e := range t.Extensions {
+		if !e.Id.Equal(oidSCTList) {
+			exts = append(exts, e)
+		}
+	}
+	t.Extensions = exts
+	return asn1.Marshal(t)
+}
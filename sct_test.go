@@ -0,0 +1,196 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSCTLeaf returns a leaf issued by ca with an SCT of logKey embedded,
+// signed like a real log would sign the precertificate.
+func testSCTLeaf(t *testing.T, ca *testCA, logKey *ecdsa.PrivateKey, timestamp time.Time) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(4711),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	create := func() *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	tbs := create().RawTBSCertificate
+
+	spki, _ := x509.MarshalPKIXPublicKey(&logKey.PublicKey)
+	logID := sha256.Sum256(spki)
+	issuerKeyHash := sha256.Sum256(ca.cert.RawSubjectPublicKeyInfo)
+	var data []byte
+	data = append(data, 0, 0)
+	data = binary.BigEndian.AppendUint64(data, uint64(timestamp.UnixMilli()))
+	data = append(data, 0, 1)
+	data = append(data, issuerKeyHash[:]...)
+	data = append(data, byte(len(tbs)>>16), byte(len(tbs)>>8), byte(len(tbs)))
+	data = append(data, tbs...)
+	data = append(data, 0, 0)
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, logKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sct []byte
+	sct = append(sct, 0)
+	sct = append(sct, logID[:]...)
+	sct = binary.BigEndian.AppendUint64(sct, uint64(timestamp.UnixMilli()))
+	sct = append(sct, 0, 0, 4, 3)
+	sct = binary.BigEndian.AppendUint16(sct, uint16(len(sig)))
+	sct = append(sct, sig...)
+	list := binary.BigEndian.AppendUint16(nil, uint16(len(sct)))
+	list = append(list, sct...)
+	list = append(binary.BigEndian.AppendUint16(nil, uint16(len(list))), list...)
+	value, _ := asn1.Marshal(list)
+
+	template.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: value}}
+	return create()
+}
+
+func TestSCTs(t *testing.T) {
+	ca := newTestCA(t, "Test CA", nil)
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	leaf := testSCTLeaf(t, ca, logKey, timestamp)
+	spki, _ := x509.MarshalPKIXPublicKey(&logKey.PublicKey)
+	sum := sha256.Sum256(spki)
+	logID := base64.StdEncoding.EncodeToString(sum[:])
+	chain := []*x509.Certificate{leaf, ca.cert}
+
+	defer func(f func() CTLogs) { embeddedCTLogs = f }(embeddedCTLogs)
+	embeddedCTLogs = func() CTLogs { return nil }
+	got := scts(chain, &Options{})
+	if len(got) != 1 || got[0].LogID != logID || !got[0].Timestamp.Equal(timestamp) || got[0].Status != "" {
+		t.Fatalf(`unexpected SCTs %+v, want one unverified of %s`, got, logID)
+	}
+	embeddedCTLogs = func() CTLogs {
+		return CTLogs{logID: {Description: "Test Log", State: "usable", Key: &logKey.PublicKey}}
+	}
+	if got := scts(chain, &Options{}); len(got) != 1 || got[0].Status != SCTValid {
+		t.Errorf(`unexpected SCTs %+v, want one valid against the embedded logs`, got)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tests := []struct {
+		logs   CTLogs
+		status string
+	}{
+		{CTLogs{logID: {Description: "Test Log", State: "usable", Key: &logKey.PublicKey}}, SCTValid},
+		{CTLogs{logID: {Description: "Test Log", State: "retired", Key: &logKey.PublicKey}}, SCTRetiredLog},
+		{CTLogs{logID: {Description: "Test Log", State: "usable", Key: &otherKey.PublicKey}}, SCTInvalid},
+		{CTLogs{logID: {Description: "Test Log", State: "pending", Key: &logKey.PublicKey}}, SCTUnknownLog},
+		{CTLogs{}, SCTUnknownLog},
+	}
+	for _, tt := range tests {
+		if got := scts(chain, &Options{CTLogs: tt.logs}); len(got) != 1 || got[0].Status != tt.status {
+			t.Errorf(`unexpected SCTs %+v, want status %s`, got, tt.status)
+		}
+	}
+
+	c := &Cert{SCTs: scts(chain, &Options{CTLogs: CTLogs{}})}
+	want := fmt.Sprintf("SCT of log %s from 2024-03-01 is unknown-log.", logID)
+	if fs := findings(c); len(fs) != 1 || fs[0].Rule != "sct" || fs[0].Message != want {
+		t.Errorf(`unexpected findings %+v, want %q`, fs, want)
+	}
+
+	if got := scts([]*x509.Certificate{ca.cert}, &Options{CTLogs: CTLogs{}}); got != nil {
+		t.Errorf(`unexpected SCTs %+v, want nil`, got)
+	}
+}
+
+func TestRemoveSCTList(t *testing.T) {
+	ca := newTestCA(t, "Test CA", nil)
+	logKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leaf := testSCTLeaf(t, ca, logKey, time.Now())
+
+	tbs, err := removeSCTList(leaf.RawTBSCertificate)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if bytes.Contains(tbs, oidSCTListDER(t)) || len(tbs) >= len(leaf.RawTBSCertificate) {
+		t.Errorf(`unexpected TBSCertificate, want it without the SCT list`)
+	}
+	if same, err := removeSCTList(ca.cert.RawTBSCertificate); err != nil || !bytes.Equal(same, ca.cert.RawTBSCertificate) {
+		t.Errorf(`unexpected TBSCertificate change, want it encoded unchanged`)
+	}
+}
+
+func oidSCTListDER(t *testing.T) []byte {
+	der, err := asn1.Marshal(oidSCTList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParseSCTListMalformed(t *testing.T) {
+	for _, b := range [][]byte{nil, {0, 5, 1}, {0, 3, 0, 1, 0}, {0, 4, 0, 2, 1, 2}} {
+		if _, err := parseSCTList(b); err == nil {
+			t.Errorf(`unexpected err nil for %x, want malformed`, b)
+		}
+	}
+}
+
+func TestLoadCTLogs(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	spki, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	path := filepath.Join(t.TempDir(), "log_list.json")
+	data := fmt.Sprintf(`{"version": "1", "operators": [{"name": "Test", "logs": [{"description": "Test Log", "log_id": "aWQ=", "key": %q, "state": {"retired": {"timestamp": "2024-01-01T00:00:00Z"}}}]}]}`, base64.StdEncoding.EncodeToString(spki))
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := LoadCTLogs(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	want := CTLogs{"aWQ=": {Description: "Test Log", State: "retired", Key: &key.PublicKey}}
+	if !reflect.DeepEqual(logs, want) {
+		t.Errorf(`unexpected logs %+v, want %+v`, logs, want)
+	}
+
+	if _, err := parseCTLogs(ctLogList, "ct_log_list.json"); err != nil {
+		t.Errorf(`unexpected err %s for the embedded log list, want nil`, err.Error())
+	}
+
+	os.WriteFile(path, []byte(`{"operators": [{"logs": [{"description": "Bad Log", "key": "AAAA"}]}]}`), 0600)
+	if _, err := LoadCTLogs(path); err == nil || !strings.HasPrefix(err.Error(), "Invalid key of log Bad Log:") {
+		t.Errorf(`unexpected err %v, want invalid key`, err)
+	}
+}
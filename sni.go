@@ -0,0 +1,78 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// SNIProbe describes the certificate a server returns for another server
+// name than the target host, or without SNI.
+type SNIProbe struct {
+	// ServerName is the name sent, empty for none.
+	ServerName string `json:"serverName,omitempty"`
+	// Same reports whether the leaf certificate is the one returned for
+	// the target host.
+	Same       bool     `json:"same"`
+	CommonName string   `json:"commonName,omitempty"`
+	Issuer     string   `json:"issuer,omitempty"`
+	SANs       []string `json:"sans,omitempty"`
+	NotAfter   string   `json:"notAfter,omitempty"`
+	// MatchesName reports whether the certificate is valid for ServerName.
+	MatchesName bool `json:"matchesName,omitempty"`
+	// Error is why the handshake failed, e.g. because the server requires
+	// SNI.
+	Error string `json:"error,omitempty"`
+}
+
+// probeSNI handshakes with ip, or host if it is empty, sending serverName
+// as SNI or none if it is empty, and compares the leaf certificate with
+// leaf. The chain is not verified.
+func probeSNI(ctx context.Context, host, ip, port, serverName string, leaf *x509.Certificate, opts *Options) SNIProbe {
+	p := SNIProbe{ServerName: serverName}
+	o := *opts
+	o.InsecureSkipVerify = true
+	o.ServerName = serverName
+	o.JA3S = false
+	o.HSTS = false
+	if o.TLSConfig != nil && o.TLSConfig.ServerName != "" {
+		o.TLSConfig = o.TLSConfig.Clone()
+		o.TLSConfig.ServerName = ""
+	}
+	target := host
+	if ip != "" {
+		target = ip
+	} else if serverName == "" {
+		// Without an IP the host name would be sent as SNI.
+		p.Error = fmt.Sprintf("No IP of %s to connect to without SNI.", host)
+		return p
+	}
+
+	info, err := serverCert(ctx, target, port, &o)
+	if err == nil && len(info.chain) == 0 {
+		err = errNoPeerCertificates
+	}
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	cert := info.chain[0]
+	p.Same = bytes.Equal(cert.Raw, leaf.Raw)
+	p.CommonName = cert.Subject.CommonName
+	p.Issuer = cert.Issuer.CommonName
+	p.SANs = cert.DNSNames
+	p.NotAfter = cert.NotAfter.In(time.Local).String()
+	p.MatchesName = serverName != "" && cert.VerifyHostname(serverName) == nil
+	return p
+}
+
+// probeServerNames runs probeSNI for each of names in turn.
+func probeServerNames(ctx context.Context, host, ip, port string, names []string, leaf *x509.Certificate, opts *Options) []SNIProbe {
+	probes := make([]SNIProbe, len(names))
+	for i, name := range names {
+		probes[i] = probeSNI(ctx, host, ip, port, name, leaf, opts)
+	}
+	return probes
+}
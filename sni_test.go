@@ -0,0 +1,79 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+)
+
+// startSNIServer serves a certificate for each server name in names and a
+// default one for other names or no SNI.
+func startSNIServer(t *testing.T, names ...string) string {
+	pair := func(name string) *tls.Certificate {
+		c := newTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: name}, DNSNames: []string{name}}, nil)
+		return &tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+	}
+	certs := map[string]*tls.Certificate{}
+	for _, name := range names {
+		certs[name] = pair(name)
+	}
+	fallback := pair("default.example")
+	_, port := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{*fallback}, GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if c, ok := certs[hello.ServerName]; ok {
+			return c, nil
+		}
+		return fallback, nil
+	}})
+	return port
+}
+
+func TestNewCertCompareNoSNI(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	port := startSNIServer(t, "localhost")
+	c := NewCertWithOptions(net.JoinHostPort("localhost", port), &Options{InsecureSkipVerify: true, CompareNoSNI: true})
+	if c.Error != "" {
+		t.Fatalf(`unexpected error %q, want ""`, c.Error)
+	}
+	if c.NoSNI == nil || c.NoSNI.Same || c.NoSNI.CommonName != "default.example" {
+		t.Errorf(`unexpected NoSNI %+v, want the default certificate`, c.NoSNI)
+	}
+
+	c = NewCertWithOptions(net.JoinHostPort("localhost", startSNIServer(t)), &Options{InsecureSkipVerify: true, CompareNoSNI: true})
+	if c.NoSNI == nil || !c.NoSNI.Same {
+		t.Errorf(`unexpected NoSNI %+v, want the same certificate`, c.NoSNI)
+	}
+}
+
+func TestNewCertServerNames(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	host := "127.0.0.1"
+	port := startSNIServer(t, "a.example", "b.example")
+	c := NewCertWithOptions(net.JoinHostPort(host, port), &Options{InsecureSkipVerify: true, ServerNames: []string{"a.example", "b.example", "c.example"}})
+	if c.Error != "" {
+		t.Fatalf(`unexpected error %q, want ""`, c.Error)
+	}
+	want := []struct {
+		commonName string
+		same       bool
+		matches    bool
+	}{
+		{"a.example", false, true},
+		{"b.example", false, true},
+		{"default.example", true, false},
+	}
+	if len(c.SNIProbes) != len(want) {
+		t.Fatalf(`unexpected %d probes, want %d`, len(c.SNIProbes), len(want))
+	}
+	for i, w := range want {
+		p := c.SNIProbes[i]
+		if p.CommonName != w.commonName || p.Same != w.same || p.MatchesName != w.matches || p.Error != "" {
+			t.Errorf(`unexpected probe %+v, want %s, same %t, matching %t`, p, w.commonName, w.same, w.matches)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sprigFuncs is a subset of the Sprig template library
+// (https://masterminds.github.io/sprig/) with the same names and argument
+// order, so templates written for Sprig work unchanged.
+var sprigFuncs = map[string]interface{}{
+	// Defaults
+	"default":  sprigDefault,
+	"empty":    empty,
+	"coalesce": coalesce,
+	"ternary": func(vt, vf interface{}, cond bool) interface{} {
+		if cond {
+			return vt
+		}
+		return vf
+	},
+
+	// Strings
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+	"quote":      func(s interface{}) string { return strconv.Quote(fmt.Sprint(s)) },
+	"indent":     func(n int, s string) string { return indent(n, s) },
+	"nindent":    func(n int, s string) string { return "\n" + indent(n, s) },
+	"join":       join,
+	"splitList":  func(sep, s string) []string { return strings.Split(s, sep) },
+
+	// Lists
+	"list":      func(v ...interface{}) []interface{} { return v },
+	"first":     func(l []string) string { return index(l, 0) },
+	"last":      func(l []string) string { return index(l, len(l)-1) },
+	"sortAlpha": sortAlpha,
+
+	// Math
+	"add": func(a, b interface{}) int64 { return toInt64(a) + toInt64(b) },
+	"sub": func(a, b interface{}) int64 { return toInt64(a) - toInt64(b) },
+	"mul": func(a, b interface{}) int64 { return toInt64(a) * toInt64(b) },
+	"div": func(a, b interface{}) int64 { return toInt64(a) / toInt64(b) },
+	"mod": func(a, b interface{}) int64 { return toInt64(a) % toInt64(b) },
+	"max": func(a, b interface{}) int64 {
+		if toInt64(a) > toInt64(b) {
+			return toInt64(a)
+		}
+		return toInt64(b)
+	},
+	"min": func(a, b interface{}) int64 {
+		if toInt64(a) < toInt64(b) {
+			return toInt64(a)
+		}
+		return toInt64(b)
+	},
+
+	// Dates
+	"now":        func() time.Time { return now() },
+	"toDate":     func(layout, s string) time.Time { t, _ := time.ParseInLocation(layout, s, time.Local); return t },
+	"dateModify": dateModify,
+	"unixEpoch":  func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) },
+	"ago":        func(t time.Time) string { return now().Sub(t).Round(time.Second).String() },
+	"duration":   func(sec interface{}) string { return (time.Duration(toInt64(sec)) * time.Second).String() },
+}
+
+func init() {
+	for name, fn := range sprigFuncs {
+		if _, ok := funcs[name]; !ok {
+			funcs[name] = fn
+		}
+	}
+}
+
+func sprigDefault(d interface{}, given ...interface{}) interface{} {
+	if len(given) == 0 || empty(given[0]) {
+		return d
+	}
+	return given[0]
+}
+
+// empty reports whether v is nil or the zero value of its type, or an
+// empty slice or map.
+func empty(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+func coalesce(v ...interface{}) interface{} {
+	for _, val := range v {
+		if !empty(val) {
+			return val
+		}
+	}
+	return nil
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.Replace(s, "\n", "\n"+pad, -1)
+}
+
+func join(sep string, v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+func index(l []string, i int) string {
+	if i < 0 || i >= len(l) {
+		return ""
+	}
+	return l[i]
+}
+
+func sortAlpha(l []string) []string {
+	sorted := append([]string(nil), l...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	case time.Duration:
+		return int64(n)
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	}
+	return 0
+}
+
+// dateModify adds the duration modifier, e.g. "-24h", to t.
+func dateModify(modifier string, t time.Time) time.Time {
+	d, err := time.ParseDuration(modifier)
+	if err != nil {
+		return t
+	}
+	return t.Add(d)
+}
@@ -0,0 +1,46 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSprigFuncs(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{{DomainName: "example.com", SANs: []string{"www.example.com", "example.com"}, NotAfter: "2018-01-31 00:00:00 +0000 UTC"}}
+	tests := []struct {
+		templ string
+		want  string
+	}{
+		{`{{range .}}{{.Error | default "none"}}{{end}}`, "none"},
+		{`{{range .}}{{.DomainName | default "none"}}{{end}}`, "example.com"},
+		{`{{range .}}{{coalesce .IP .DomainName}}{{end}}`, "example.com"},
+		{`{{range .}}{{ternary "ok" "bad" (empty .Error)}}{{end}}`, "ok"},
+		{`{{range .}}{{.DomainName | upper | trimSuffix ".COM"}}{{end}}`, "EXAMPLE"},
+		{`{{range .}}{{if .DomainName | hasSuffix ".com"}}yes{{end}}{{end}}`, "yes"},
+		{`{{range .}}{{.DomainName | replace "." "_" | quote}}{{end}}`, `"example_com"`},
+		{`{{range .}}{{sortAlpha .SANs | join ","}}{{end}}`, "example.com,www.example.com"},
+		{`{{range .}}{{first .SANs}} {{last .SANs}}{{end}}`, "www.example.com example.com"},
+		{`{{join "-" (list 1 "a")}}`, "1-a"},
+		{`{{splitList "." "a.b.c" | join " "}}`, "a b c"},
+		{`{{"a\nb" | indent 2}}`, "  a\n  b"},
+		{`{{add 1 2}} {{sub 5 3}} {{mul 2 3}} {{div 7 2}} {{mod 7 2}} {{max 1 2}} {{min 1 2}}`, "3 2 6 3 1 2 1"},
+		{`{{range .}}{{(toDate "2006-01-02 15:04:05 -0700 MST" .NotAfter).Sub now}}{{end}}`, "720h0m0s"},
+		{`{{now | dateModify "-24h" | unixEpoch}}`, "1514678400"},
+		{`{{duration 90}}`, "1m30s"},
+		{`{{now | dateModify "-1h" | ago}}`, "1h0m0s"},
+		{`{{repeat 3 "ab"}}`, "ababab"},
+	}
+	for _, test := range tests {
+		got, err := certs.Template(test.templ)
+		if err != nil {
+			t.Errorf(`unexpected err %s for %s, want nil`, err.Error(), test.templ)
+			continue
+		}
+		if got != test.want {
+			t.Errorf(`unexpected return value %q for %s, want %q`, got, test.templ, test.want)
+		}
+	}
+}
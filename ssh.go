@@ -0,0 +1,339 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const sshTempl = `{{range .}}DomainName:        {{.DomainName}}
+IP:                {{.IP}}
+Banner:            {{.Banner}}
+Type:              {{.Type}}
+FingerprintSHA256: {{.FingerprintSHA256}}
+FingerprintMD5:    {{.FingerprintMD5}}
+Error:             {{.Error}}
+
+{{end}}
+`
+
+const defaultSSHPort = "22"
+
+const sshTimeout = 10 * time.Second
+
+const (
+	sshMsgIgnore       = 2
+	sshMsgDebug        = 4
+	sshMsgKexInit      = 20
+	sshMsgKexECDHInit  = 30
+	sshMsgKexECDHReply = 31
+)
+
+var sshKexCurves = []struct {
+	name  string
+	curve ecdh.Curve
+}{
+	{"curve25519-sha256", ecdh.X25519()},
+	{"curve25519-sha256@libssh.org", ecdh.X25519()},
+	{"ecdh-sha2-nistp256", ecdh.P256()},
+	{"ecdh-sha2-nistp384", ecdh.P384()},
+	{"ecdh-sha2-nistp521", ecdh.P521()},
+}
+
+const sshHostKeyAlgorithms = "ssh-ed25519,ecdsa-sha2-nistp256,ecdsa-sha2-nistp384,ecdsa-sha2-nistp521,rsa-sha2-512,rsa-sha2-256,ssh-rsa"
+
+type HostKeys []*HostKey
+
+// HostKey is the key an SSH server presents during key exchange.
+type HostKey struct {
+	DomainName        string `json:"domainName"`
+	IP                string `json:"ip"`
+	Banner            string `json:"banner"`
+	Type              string `json:"type"`
+	FingerprintSHA256 string `json:"fingerprintSHA256"`
+	FingerprintMD5    string `json:"fingerprintMD5"`
+	Error             string `json:"error"`
+}
+
+var serverHostKey = func(ctx context.Context, host, port string, opts *Options) (string, string, []byte, error) {
+	conn, proxied, err := dialTarget(ctx, host, port, opts)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(sshTimeout))
+	}
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+	var ip string
+	if !proxied {
+		ip, _, _ = net.SplitHostPort(conn.RemoteAddr().String())
+	}
+
+	banner, key, err := sshKeyExchange(conn)
+	return ip, banner, key, err
+}
+
+// NewHostKey fetches the SSH host key of hostport. The port defaults to 22.
+func NewHostKey(hostport string) *HostKey {
+	return NewHostKeyWithOptions(hostport, nil)
+}
+
+// NewHostKeyWithOptions fetches the SSH host key of hostport with the
+// proxy, local address, resolver and timeout of opts.
+func NewHostKeyWithOptions(hostport string, opts *Options) *HostKey {
+	opts = opts.orDefault()
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = hostport, defaultSSHPort
+	}
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	return newHostKey(ctx, host, port, opts)
+}
+
+func newHostKey(ctx context.Context, host, port string, opts *Options) *HostKey {
+	ip, banner, blob, err := serverHostKey(ctx, host, port, opts)
+	if err != nil {
+		return &HostKey{DomainName: host, IP: ip, Banner: banner, Error: err.Error()}
+	}
+	typ, _, _ := sshString(blob)
+	sha := sha256.Sum256(blob)
+	md := md5.Sum(blob)
+	hexParts := make([]string, len(md))
+	for i, b := range md {
+		hexParts[i] = fmt.Sprintf("%02x", b)
+	}
+	return &HostKey{
+		DomainName:        host,
+		IP:                ip,
+		Banner:            banner,
+		Type:              string(typ),
+		FingerprintSHA256: "SHA256:" + base64.RawStdEncoding.EncodeToString(sha[:]),
+		FingerprintMD5:    "MD5:" + strings.Join(hexParts, ":"),
+	}
+}
+
+// NewHostKeys fetches the SSH host keys of the given hosts concurrently.
+func NewHostKeys(s []string) (HostKeys, error) {
+	if err := validate(s); err != nil {
+		return nil, err
+	}
+	keys := make(HostKeys, len(s))
+	done := make(chan struct{}, len(s))
+	for i, d := range s {
+		go func(i int, d string) {
+			tokens <- struct{}{}
+			keys[i] = NewHostKey(d)
+			<-tokens
+			done <- struct{}{}
+		}(i, d)
+	}
+	for range s {
+		<-done
+	}
+	return keys, nil
+}
+
+func (keys HostKeys) String() string {
+	return execute("ssh", sshTempl, keys)
+}
+
+func (keys HostKeys) JSON() []byte {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// sshKeyExchange runs an SSH key exchange far enough to receive the
+// server's host key blob. The key is not authenticated.
+func sshKeyExchange(conn io.ReadWriter) (string, []byte, error) {
+	if _, err := io.WriteString(conn, "SSH-2.0-cert\r\n"); err != nil {
+		return "", nil, err
+	}
+	r := bufio.NewReader(conn)
+	var banner string
+	for i := 0; ; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			banner = strings.TrimRight(line, "\r\n")
+			break
+		}
+		if i > 32 {
+			return "", nil, errors.New("ssh: no version banner")
+		}
+	}
+
+	cookie := make([]byte, 16)
+	rand.Read(cookie)
+	var kexNames []string
+	for _, k := range sshKexCurves {
+		kexNames = append(kexNames, k.name)
+	}
+	var kexinit bytes.Buffer
+	kexinit.WriteByte(sshMsgKexInit)
+	kexinit.Write(cookie)
+	for _, list := range []string{
+		strings.Join(kexNames, ","),
+		sshHostKeyAlgorithms,
+		"aes128-ctr,aes256-ctr,aes128-gcm@openssh.com,chacha20-poly1305@openssh.com",
+		"aes128-ctr,aes256-ctr,aes128-gcm@openssh.com,chacha20-poly1305@openssh.com",
+		"hmac-sha2-256,hmac-sha2-512,hmac-sha1",
+		"hmac-sha2-256,hmac-sha2-512,hmac-sha1",
+		"none",
+		"none",
+		"",
+		"",
+	} {
+		writeSSHString(&kexinit, []byte(list))
+	}
+	kexinit.Write([]byte{0, 0, 0, 0, 0})
+	if err := writeSSHPacket(conn, kexinit.Bytes()); err != nil {
+		return banner, nil, err
+	}
+
+	serverKexinit, err := readSSHMessage(r, sshMsgKexInit)
+	if err != nil {
+		return banner, nil, err
+	}
+	serverKex, _, ok := sshString(serverKexinit[17:])
+	if !ok {
+		return banner, nil, errors.New("ssh: malformed KEXINIT")
+	}
+	var curve ecdh.Curve
+	for _, k := range sshKexCurves {
+		if containsName(string(serverKex), k.name) {
+			curve = k.curve
+			break
+		}
+	}
+	if curve == nil {
+		return banner, nil, fmt.Errorf("ssh: no common key exchange algorithm in %q", serverKex)
+	}
+
+	priv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return banner, nil, err
+	}
+	var init bytes.Buffer
+	init.WriteByte(sshMsgKexECDHInit)
+	writeSSHString(&init, priv.PublicKey().Bytes())
+	if err := writeSSHPacket(conn, init.Bytes()); err != nil {
+		return banner, nil, err
+	}
+
+	reply, err := readSSHMessage(r, sshMsgKexECDHReply)
+	if err != nil {
+		return banner, nil, err
+	}
+	hostKey, _, ok := sshString(reply[1:])
+	if !ok {
+		return banner, nil, errors.New("ssh: malformed KEX_ECDH_REPLY")
+	}
+	return banner, hostKey, nil
+}
+
+func containsName(list, name string) bool {
+	for _, n := range strings.Split(list, ",") {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSSHString(b *bytes.Buffer, s []byte) {
+	binary.Write(b, binary.BigEndian, uint32(len(s)))
+	b.Write(s)
+}
+
+func sshString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+func writeSSHPacket(w io.Writer, payload []byte) error {
+	padding := 8 - (5+len(payload))%8
+	if padding < 4 {
+		padding += 8
+	}
+	packet := make([]byte, 5+len(payload)+padding)
+	binary.BigEndian.PutUint32(packet, uint32(1+len(payload)+padding))
+	packet[4] = byte(padding)
+	copy(packet[5:], payload)
+	rand.Read(packet[5+len(payload):])
+	_, err := w.Write(packet)
+	return err
+}
+
+func readSSHPacket(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length < padding+1 || length > 256*1024 {
+		return nil, errors.New("ssh: invalid packet length")
+	}
+	rest := make([]byte, length-1)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, err
+	}
+	return rest[:length-1-padding], nil
+}
+
+// readSSHMessage returns the next message, skipping ignore and debug
+// messages, and fails unless it is of type want.
+func readSSHMessage(r io.Reader, want byte) ([]byte, error) {
+	for {
+		msg, err := readSSHPacket(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) == 0 {
+			return nil, errors.New("ssh: empty message")
+		}
+		switch msg[0] {
+		case sshMsgIgnore, sshMsgDebug:
+			continue
+		case want:
+			if want == sshMsgKexInit && len(msg) < 17 {
+				return nil, errors.New("ssh: malformed KEXINIT")
+			}
+			return msg, nil
+		}
+		return nil, fmt.Errorf("ssh: unexpected message type %d, want %d", msg[0], want)
+	}
+}
@@ -0,0 +1,158 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeSSHServer answers a single key exchange with a fixed host key blob.
+func fakeSSHServer(t *testing.T, blob []byte) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		conn.Write([]byte("a pre-banner line\r\nSSH-2.0-OpenSSH_9.6 test\r\n"))
+		if _, err := r.ReadString('\n'); err != nil {
+			return
+		}
+
+		var kexinit bytes.Buffer
+		kexinit.WriteByte(sshMsgKexInit)
+		kexinit.Write(make([]byte, 16))
+		for _, list := range []string{"sntrup761x25519-sha512@openssh.com,curve25519-sha256", "ssh-ed25519", "aes128-ctr", "aes128-ctr", "hmac-sha2-256", "hmac-sha2-256", "none", "none", "", ""} {
+			writeSSHString(&kexinit, []byte(list))
+		}
+		kexinit.Write(make([]byte, 5))
+		writeSSHPacket(conn, []byte{sshMsgIgnore})
+		writeSSHPacket(conn, kexinit.Bytes())
+
+		if _, err := readSSHMessage(r, sshMsgKexInit); err != nil {
+			return
+		}
+		if _, err := readSSHMessage(r, sshMsgKexECDHInit); err != nil {
+			return
+		}
+		var reply bytes.Buffer
+		reply.WriteByte(sshMsgKexECDHReply)
+		writeSSHString(&reply, blob)
+		writeSSHString(&reply, make([]byte, 32))
+		writeSSHString(&reply, []byte("signature"))
+		writeSSHPacket(conn, reply.Bytes())
+	}()
+	return l.Addr().String()
+}
+
+func TestNewHostKey(t *testing.T) {
+	var blob bytes.Buffer
+	writeSSHString(&blob, []byte("ssh-ed25519"))
+	writeSSHString(&blob, make([]byte, 32))
+
+	addr := fakeSSHServer(t, blob.Bytes())
+	k := NewHostKey(addr)
+	if k.Error != "" {
+		t.Fatalf(`unexpected HostKey.Error %q, want %q`, k.Error, "")
+	}
+	if k.Banner != "SSH-2.0-OpenSSH_9.6 test" {
+		t.Errorf(`unexpected HostKey.Banner %q, want %q`, k.Banner, "SSH-2.0-OpenSSH_9.6 test")
+	}
+	if k.Type != "ssh-ed25519" {
+		t.Errorf(`unexpected HostKey.Type %q, want %q`, k.Type, "ssh-ed25519")
+	}
+	sum := sha256.Sum256(blob.Bytes())
+	if want := "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]); k.FingerprintSHA256 != want {
+		t.Errorf(`unexpected HostKey.FingerprintSHA256 %q, want %q`, k.FingerprintSHA256, want)
+	}
+	if len(k.FingerprintMD5) != len("MD5:")+16*3-1 {
+		t.Errorf(`unexpected HostKey.FingerprintMD5 %q`, k.FingerprintMD5)
+	}
+}
+
+func TestNewHostKeyError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	if k := NewHostKey(addr); k.Error == "" {
+		t.Error(`unexpected empty HostKey.Error, want error`)
+	}
+}
+
+func TestHostKeysAsString(t *testing.T) {
+	keys := HostKeys{{DomainName: "example.com", IP: "127.0.0.1", Type: "ssh-ed25519"}}
+	expected := `DomainName:        example.com
+IP:                127.0.0.1
+Banner:            
+Type:              ssh-ed25519
+FingerprintSHA256: 
+FingerprintMD5:    
+Error:             
+
+
+`
+	if keys.String() != expected {
+		t.Errorf(`unexpected return value %q, want %q`, keys.String(), expected)
+	}
+}
+
+func TestNewHostKeyThroughProxy(t *testing.T) {
+	var blob bytes.Buffer
+	writeSSHString(&blob, []byte("ssh-ed25519"))
+	writeSSHString(&blob, make([]byte, 32))
+
+	addr := fakeSSHServer(t, blob.Bytes())
+	proxyAddr, auths := fakeProxy(t)
+	k := NewHostKeyWithOptions(addr, &Options{Proxy: ProxyMap{"*": "http://user:secret@" + proxyAddr}.Proxy, Timeout: 5 * time.Second})
+	if k.Error != "" || k.Type != "ssh-ed25519" {
+		t.Fatalf(`unexpected HostKey %+v, want ssh-ed25519 key`, k)
+	}
+	if k.IP != "" {
+		t.Errorf(`unexpected HostKey.IP %q, want empty through proxy`, k.IP)
+	}
+	if auth := <-auths; auth == "" {
+		t.Error(`unexpected empty Proxy-Authorization, want connection through proxy`)
+	}
+}
+
+func TestNewCertWithSSH(t *testing.T) {
+	stubCert()
+	defer func(f func(context.Context, string, string, *Options) (string, string, []byte, error)) {
+		serverHostKey = f
+	}(serverHostKey)
+	var ports []string
+	serverHostKey = func(ctx context.Context, host, port string, opts *Options) (string, string, []byte, error) {
+		ports = append(ports, port)
+		var blob bytes.Buffer
+		writeSSHString(&blob, []byte("ssh-ed25519"))
+		return "127.0.0.1", "SSH-2.0-test", blob.Bytes(), nil
+	}
+
+	c := NewCertWithOptions("example.com", &Options{SSH: true})
+	if c.Error != "" || c.SSHHostKey == nil || c.SSHHostKey.Type != "ssh-ed25519" {
+		t.Fatalf(`unexpected Cert %+v, want certificate and SSH host key`, c)
+	}
+	if want := []string{"22"}; !reflect.DeepEqual(ports, want) {
+		t.Errorf(`unexpected ports %q, want %q`, ports, want)
+	}
+	if c := NewCertWithOptions("example.com", nil); c.SSHHostKey != nil {
+		t.Errorf(`unexpected Cert.SSHHostKey %+v, want nil`, c.SSHHostKey)
+	}
+}
@@ -0,0 +1,216 @@
+package cert
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// StartTLSNone disables STARTTLS in Options.StartTLS and Target.StartTLS,
+// also on ports that default to it.
+const StartTLSNone = "none"
+
+// startTLSProtocols negotiate TLS on a plaintext connection.
+var startTLSProtocols = map[string]func(conn net.Conn) error{
+	"smtp":     startTLSSMTP,
+	"pop3":     startTLSPOP3,
+	"imap":     startTLSIMAP,
+	"ldap":     startTLSLDAP,
+	"postgres": startTLSPostgres,
+}
+
+// startTLSPorts are the well-known ports that speak STARTTLS.
+var startTLSPorts = map[string]string{
+	"25":   "smtp",
+	"587":  "smtp",
+	"110":  "pop3",
+	"143":  "imap",
+	"389":  "ldap",
+	"5432": "postgres",
+}
+
+// startTLS returns the STARTTLS protocol to speak on port, or "" for none.
+func (opts *Options) startTLS(port string) (string, error) {
+	switch opts.StartTLS {
+	case "":
+		return startTLSPorts[port], nil
+	case StartTLSNone:
+		return "", nil
+	}
+	if _, ok := startTLSProtocols[opts.StartTLS]; !ok {
+		return "", fmt.Errorf("Unknown STARTTLS protocol %q.", opts.StartTLS)
+	}
+	return opts.StartTLS, nil
+}
+
+// negotiateStartTLS runs protocol on conn within the deadline of ctx.
+func negotiateStartTLS(ctx context.Context, conn net.Conn, protocol string, opts *Options) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	opts.logDebug("starttls", "protocol", protocol, "addr", conn.RemoteAddr().String())
+	if err := startTLSProtocols[protocol](conn); err != nil {
+		return fmt.Errorf("STARTTLS %s failed: %v.", protocol, err)
+	}
+	return nil
+}
+
+func startTLSSMTP(conn net.Conn) error {
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		return err
+	}
+	if err := text.PrintfLine("EHLO cert"); err != nil {
+		return err
+	}
+	_, msg, err := text.ReadResponse(250)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(strings.ToUpper(msg), "STARTTLS") {
+		return fmt.Errorf("server does not offer STARTTLS")
+	}
+	if err := text.PrintfLine("STARTTLS"); err != nil {
+		return err
+	}
+	_, _, err = text.ReadResponse(220)
+	return err
+}
+
+func startTLSPOP3(conn net.Conn) error {
+	text := textproto.NewConn(conn)
+	for _, cmd := range []string{"", "STLS"} {
+		if cmd != "" {
+			if err := text.PrintfLine("%s", cmd); err != nil {
+				return err
+			}
+		}
+		line, err := text.ReadLine()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, "+OK") {
+			return fmt.Errorf("unexpected response %q", line)
+		}
+	}
+	return nil
+}
+
+func startTLSIMAP(conn net.Conn) error {
+	text := textproto.NewConn(conn)
+	line, err := text.ReadLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	if err := text.PrintfLine("a1 STARTTLS"); err != nil {
+		return err
+	}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "a1 ") {
+			if !strings.HasPrefix(line, "a1 OK") {
+				return fmt.Errorf("unexpected response %q", line)
+			}
+			return nil
+		}
+	}
+}
+
+// ldapStartTLS is an LDAPMessage with message ID 1 holding an
+// ExtendedRequest for the StartTLS OID 1.3.6.1.4.1.1466.20037.
+var ldapStartTLS = append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, "1.3.6.1.4.1.1466.20037"...)
+
+func startTLSLDAP(conn net.Conn) error {
+	if _, err := conn.Write(ldapStartTLS); err != nil {
+		return err
+	}
+	// LDAPMessage ::= SEQUENCE { messageID, ExtendedResponse [APPLICATION 24]
+	// { resultCode ENUMERATED, ... } }
+	r := bufio.NewReader(conn)
+	msg, err := readBER(r, 0x30)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReader(bytes.NewReader(msg))
+	if _, err := readBER(br, 0x02); err != nil {
+		return err
+	}
+	resp, err := readBER(br, 0x78)
+	if err != nil {
+		return err
+	}
+	code, err := readBER(bufio.NewReader(bytes.NewReader(resp)), 0x0a)
+	if err != nil {
+		return err
+	}
+	if len(code) != 1 || code[0] != 0 {
+		return fmt.Errorf("result code %v", code)
+	}
+	return nil
+}
+
+// readBER reads one BER element with the given tag and returns its
+// contents.
+func readBER(r *bufio.Reader, tag byte) ([]byte, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if t != tag {
+		return nil, fmt.Errorf("unexpected BER tag %#x, want %#x", t, tag)
+	}
+	l, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	n := int(l)
+	if l&0x80 != 0 {
+		size := int(l & 0x7f)
+		if size == 0 || size > 3 {
+			return nil, fmt.Errorf("unsupported BER length")
+		}
+		n = 0
+		for i := 0; i < size; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			n = n<<8 | int(b)
+		}
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func startTLSPostgres(conn net.Conn) error {
+	// SSLRequest: length 8 and the code 1234 5679.
+	req := make([]byte, 8)
+	binary.BigEndian.PutUint32(req, 8)
+	binary.BigEndian.PutUint32(req[4:], 80877103)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(conn, b); err != nil {
+		return err
+	}
+	if b[0] != 'S' {
+		return fmt.Errorf("server does not support SSL")
+	}
+	return nil
+}
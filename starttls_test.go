@@ -0,0 +1,160 @@
+package cert
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeStartTLSServer runs greet on each connection and then serves TLS on
+// it if greet succeeds.
+func fakeStartTLSServer(t *testing.T, greet func(conn net.Conn, r *bufio.Reader) bool) (string, string) {
+	leaf := newTestCert(t, &x509.Certificate{DNSNames: []string{"localhost"}}, nil)
+	config := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{leaf.cert.Raw}, PrivateKey: leaf.key}}}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if greet(conn, bufio.NewReader(conn)) {
+					tls.Server(conn, config).Handshake()
+				}
+			}()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	return host, port
+}
+
+// expect reads a line from r and reports whether it is want.
+func expect(r *bufio.Reader, want string) bool {
+	line, err := r.ReadString('\n')
+	return err == nil && strings.TrimRight(line, "\r\n") == want
+}
+
+func TestStartTLS(t *testing.T) {
+	tests := []struct {
+		protocol string
+		greet    func(conn net.Conn, r *bufio.Reader) bool
+	}{
+		{"smtp", func(conn net.Conn, r *bufio.Reader) bool {
+			io.WriteString(conn, "220-mail.example.com ESMTP\r\n220 ready\r\n")
+			if !expect(r, "EHLO cert") {
+				return false
+			}
+			io.WriteString(conn, "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+			if !expect(r, "STARTTLS") {
+				return false
+			}
+			io.WriteString(conn, "220 go ahead\r\n")
+			return true
+		}},
+		{"pop3", func(conn net.Conn, r *bufio.Reader) bool {
+			io.WriteString(conn, "+OK POP3 ready\r\n")
+			if !expect(r, "STLS") {
+				return false
+			}
+			io.WriteString(conn, "+OK begin TLS\r\n")
+			return true
+		}},
+		{"imap", func(conn net.Conn, r *bufio.Reader) bool {
+			io.WriteString(conn, "* OK IMAP4rev1 ready\r\n")
+			if !expect(r, "a1 STARTTLS") {
+				return false
+			}
+			io.WriteString(conn, "* CAPABILITY IMAP4rev1\r\na1 OK begin TLS\r\n")
+			return true
+		}},
+		{"ldap", func(conn net.Conn, r *bufio.Reader) bool {
+			req := make([]byte, len(ldapStartTLS))
+			if _, err := io.ReadFull(r, req); err != nil || string(req) != string(ldapStartTLS) {
+				return false
+			}
+			// ExtendedResponse with resultCode success, empty matchedDN and
+			// diagnosticMessage.
+			conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+			return true
+		}},
+		{"postgres", func(conn net.Conn, r *bufio.Reader) bool {
+			req := make([]byte, 8)
+			if _, err := io.ReadFull(r, req); err != nil || string(req) != "\x00\x00\x00\x08\x04\xd2\x16\x2f" {
+				return false
+			}
+			conn.Write([]byte{'S'})
+			return true
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.protocol, func(t *testing.T) {
+			host, port := fakeStartTLSServer(t, test.greet)
+			info, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true, StartTLS: test.protocol, HSTS: true})
+			if err != nil {
+				t.Fatalf(`unexpected err %s, want nil`, err.Error())
+			}
+			if len(info.chain) == 0 || info.startTLS != test.protocol {
+				t.Errorf(`unexpected result %d certificates via %q, want 1 via %q`, len(info.chain), info.startTLS, test.protocol)
+			}
+			if info.hsts != nil {
+				t.Errorf(`unexpected HSTS %+v over STARTTLS, want nil`, info.hsts)
+			}
+		})
+	}
+}
+
+func TestStartTLSRefused(t *testing.T) {
+	host, port := fakeStartTLSServer(t, func(conn net.Conn, r *bufio.Reader) bool {
+		io.WriteString(conn, "220 ready\r\n")
+		expect(r, "EHLO cert")
+		io.WriteString(conn, "250 mail.example.com\r\n")
+		return false
+	})
+	_, err := realServerCert(context.Background(), host, port, &Options{InsecureSkipVerify: true, StartTLS: "smtp"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS smtp failed") {
+		t.Errorf(`unexpected err %v, want STARTTLS failure`, err)
+	}
+}
+
+func TestOptionsStartTLS(t *testing.T) {
+	tests := []struct {
+		option string
+		port   string
+		want   string
+	}{
+		{"", "25", "smtp"},
+		{"", "587", "smtp"},
+		{"", "110", "pop3"},
+		{"", "143", "imap"},
+		{"", "389", "ldap"},
+		{"", "5432", "postgres"},
+		{"", "443", ""},
+		{"none", "25", ""},
+		{"imap", "1143", "imap"},
+	}
+	for _, test := range tests {
+		got, err := (&Options{StartTLS: test.option}).startTLS(test.port)
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if got != test.want {
+			t.Errorf(`unexpected protocol %q for %q on port %s, want %q`, got, test.option, test.port, test.want)
+		}
+	}
+
+	if _, err := (&Options{StartTLS: "xmpp"}).startTLS("5222"); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
@@ -0,0 +1,136 @@
+package cert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WatchState is what a watcher remembers between runs: the thresholds each
+// certificate has fired and the last results, so a restarted watcher
+// neither repeats notifications nor loses track of changes.
+type WatchState struct {
+	Ladder *Ladder
+	Last   Certs
+}
+
+// Change is a difference of a domain's result from the previous run.
+type Change struct {
+	// DomainName is followed by the port unless it is the default one, so
+	// that ports of a host are told apart.
+	DomainName string `json:"domainName"`
+	Field      string `json:"field"`
+	Old        string `json:"old"`
+	New        string `json:"new"`
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Field == "":
+		if c.Old == "" {
+			return fmt.Sprintf("%s is new.", c.DomainName)
+		}
+		return fmt.Sprintf("%s is gone.", c.DomainName)
+	case c.Old == "":
+		return fmt.Sprintf("%s %s is now %q.", c.DomainName, c.Field, c.New)
+	}
+	return fmt.Sprintf("%s %s changed from %q to %q.", c.DomainName, c.Field, c.Old, c.New)
+}
+
+type watchStateFile struct {
+	Fired map[string]time.Duration `json:"fired"`
+	Last  Certs                    `json:"last"`
+}
+
+// LoadWatchState reads the state saved at path, using thresholds for its
+// Ladder. A missing file yields an empty state.
+func LoadWatchState(path string, thresholds ...time.Duration) (*WatchState, error) {
+	s := &WatchState{Ladder: NewLadder(thresholds...)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f watchStateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("Invalid watch state %s: %v.", path, err)
+	}
+	if err := restoreCerts(f.Last); err != nil {
+		return nil, fmt.Errorf("Invalid watch state %s: %v", path, err)
+	}
+	for k, v := range f.Fired {
+		s.Ladder.fired[k] = v
+	}
+	s.Last = f.Last
+	return s, nil
+}
+
+// Save writes the state to path, replacing it atomically.
+func (s *WatchState) Save(path string) error {
+	data, err := json.Marshal(watchStateFile{Fired: s.Ladder.fired, Last: s.Last})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Update records certs as the latest results and returns the notifications
+// due and the changes since the previous results. Hosts reported as gone
+// are dropped from Last, along with the thresholds they fired, so the
+// state only holds the hosts of the latest run.
+func (s *WatchState) Update(certs Certs) ([]Notification, []Change) {
+	notifications := s.Ladder.Check(certs)
+
+	var changes []Change
+	last := map[string]*Cert{}
+	for _, c := range s.Last {
+		last[hostport(c)] = c
+	}
+	seen := map[string]bool{}
+	for _, c := range certs {
+		seen[hostport(c)] = true
+		prev, ok := last[hostport(c)]
+		if !ok {
+			if s.Last != nil {
+				changes = append(changes, Change{DomainName: hostport(c), New: c.Status})
+			}
+			continue
+		}
+		for _, f := range [][3]string{
+			{"Status", prev.Status, c.Status},
+			{"NotAfter", prev.NotAfter, c.NotAfter},
+			{"Issuer", prev.Issuer, c.Issuer},
+			{"SANs", strings.Join(prev.SANs, " "), strings.Join(c.SANs, " ")},
+			{"Error", prev.Error, c.Error},
+		} {
+			if f[1] != f[2] {
+				changes = append(changes, Change{DomainName: hostport(c), Field: f[0], Old: f[1], New: f[2]})
+			}
+		}
+	}
+	for _, c := range s.Last {
+		if !seen[hostport(c)] {
+			changes = append(changes, Change{DomainName: hostport(c), Old: c.Status})
+		}
+	}
+
+	s.Last = certs
+	return notifications, changes
+}
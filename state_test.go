@@ -0,0 +1,108 @@
+package cert
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchState(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := LoadWatchState(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	first := Certs{
+		{DomainName: "a.example.com", Issuer: "Old CA", NotAfter: "2018-01-10 00:00:00 +0000 UTC", Status: StatusExpiring},
+		{DomainName: "b.example.com", Issuer: "Old CA", NotAfter: "2018-06-01 00:00:00 +0000 UTC", Status: StatusOK},
+	}
+	ns, changes := s.Update(first)
+	if len(ns) != 1 || ns[0].DomainName != "a.example.com" {
+		t.Errorf(`unexpected notifications %v, want one for a.example.com`, ns)
+	}
+	if changes != nil {
+		t.Errorf(`unexpected changes %v on first run, want none`, changes)
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	s, err = LoadWatchState(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	second := Certs{
+		{DomainName: "a.example.com", Issuer: "Old CA", NotAfter: "2018-01-10 00:00:00 +0000 UTC", Status: StatusExpiring},
+		{DomainName: "b.example.com", Issuer: "New CA", NotAfter: "2018-09-01 00:00:00 +0000 UTC", Status: StatusOK},
+		{DomainName: "c.example.com", Status: StatusError, Error: "connection refused"},
+	}
+	ns, changes = s.Update(second)
+	if ns != nil {
+		t.Errorf(`unexpected notifications %v after restart, want none`, ns)
+	}
+	want := []Change{
+		{DomainName: "b.example.com", Field: "NotAfter", Old: "2018-06-01 00:00:00 +0000 UTC", New: "2018-09-01 00:00:00 +0000 UTC"},
+		{DomainName: "b.example.com", Field: "Issuer", Old: "Old CA", New: "New CA"},
+		{DomainName: "c.example.com", New: StatusError},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf(`unexpected changes %v, want %v`, changes, want)
+	}
+
+	_, changes = s.Update(second[:2])
+	if want := []Change{{DomainName: "c.example.com", Old: StatusError}}; !reflect.DeepEqual(changes, want) {
+		t.Errorf(`unexpected changes %v, want %v`, changes, want)
+	}
+
+	// Once reported as gone, a host is not kept in the saved state.
+	_, changes = s.Update(second[1:2])
+	if want := []Change{{DomainName: "a.example.com", Old: StatusExpiring}}; !reflect.DeepEqual(changes, want) {
+		t.Errorf(`unexpected changes %v, want %v`, changes, want)
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	s, err = LoadWatchState(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(s.Last) != 1 || s.Last[0].DomainName != "b.example.com" || len(s.Ladder.fired) != 0 {
+		t.Errorf(`unexpected state %v and thresholds %v, want only b.example.com`, s.Last, s.Ladder.fired)
+	}
+	if _, changes = s.Update(second[1:2]); changes != nil {
+		t.Errorf(`unexpected changes %v, want none`, changes)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	var tests = []struct {
+		change Change
+		want   string
+	}{
+		{Change{DomainName: "example.com", New: StatusOK}, "example.com is new."},
+		{Change{DomainName: "example.com", Old: StatusOK}, "example.com is gone."},
+		{Change{DomainName: "example.com", Field: "Error", New: "refused"}, `example.com Error is now "refused".`},
+		{Change{DomainName: "example.com", Field: "Issuer", Old: "A", New: "B"}, `example.com Issuer changed from "A" to "B".`},
+	}
+	for _, test := range tests {
+		if got := test.change.String(); got != test.want {
+			t.Errorf(`unexpected return value %q, want %q`, got, test.want)
+		}
+	}
+}
+
+func TestLoadWatchStateNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"last":[null]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadWatchState(path); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
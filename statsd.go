@@ -0,0 +1,86 @@
+package cert
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// statsdPacketSize keeps packets below a typical Ethernet MTU.
+const statsdPacketSize = 1432
+
+// StatsdSink emits metrics of each scan to a statsd server, with
+// DogStatsD tags:
+//
+//	<prefix>.days_remaining  gauge per domain
+//	<prefix>.errors          counter per failed domain, tagged by error kind
+//	<prefix>.scans           counter per Send
+type StatsdSink struct {
+	// Addr is the UDP address of the server, e.g. "localhost:8125".
+	Addr string `json:"addr"`
+	// Prefix defaults to "cert".
+	Prefix string `json:"prefix,omitempty"`
+	// Tags are added to every metric, e.g. "env:prod".
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Send emits the metrics for certs.
+func (s *StatsdSink) Send(certs Certs) error {
+	conn, err := net.Dial("udp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var packet strings.Builder
+	send := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+	for _, line := range s.metrics(certs) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdPacketSize {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return send()
+}
+
+func (s *StatsdSink) metrics(certs Certs) []string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "cert"
+	}
+	metric := func(name, value, typ string, tags ...string) string {
+		tags = append(tags, s.Tags...)
+		line := fmt.Sprintf("%s.%s:%s|%s", prefix, name, value, typ)
+		if len(tags) > 0 {
+			line += "|#" + strings.Join(tags, ",")
+		}
+		return line
+	}
+
+	lines := []string{metric("scans", "1", "c")}
+	for _, c := range certs {
+		domain := "domain:" + hostport(c)
+		if c.Error != "" {
+			lines = append(lines, metric("errors", "1", "c", domain, "kind:"+c.ErrorKind))
+			continue
+		}
+		if notAfter, ok := c.expiry(); ok {
+			days := float64(notAfter.Sub(now())) / float64(24*time.Hour)
+			lines = append(lines, metric("days_remaining", fmt.Sprintf("%.2f", days), "g", domain))
+		}
+	}
+	return lines
+}
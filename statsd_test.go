@@ -0,0 +1,75 @@
+package cert
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsdSinkSend(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	s := &StatsdSink{Addr: l.LocalAddr().String(), Tags: []string{"env:test"}}
+	certs := Certs{
+		{DomainName: "example.com", NotAfter: "2018-01-31 12:00:00 +0000 UTC"},
+		{DomainName: "down.example.com", Error: "connection refused", ErrorKind: ErrorKindRefused},
+	}
+	if err := s.Send(certs); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	buf := make([]byte, 2048)
+	l.SetDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := l.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"cert.scans:1|c|#env:test",
+		"cert.days_remaining:30.50|g|#domain:example.com,env:test",
+		"cert.errors:1|c|#domain:down.example.com,kind:refused,env:test",
+	}
+	if got := strings.Split(string(buf[:n]), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected metrics %q, want %q`, got, want)
+	}
+}
+
+func TestStatsdSinkPacketSize(t *testing.T) {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	var certs Certs
+	for i := 0; i < 100; i++ {
+		certs = append(certs, &Cert{DomainName: strings.Repeat("x", 40) + ".example.com", Error: "x", ErrorKind: ErrorKindOther})
+	}
+	s := &StatsdSink{Addr: l.LocalAddr().String()}
+	if err := s.Send(certs); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	lines := 0
+	buf := make([]byte, 65536)
+	for lines < 101 {
+		l.SetDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := l.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > statsdPacketSize {
+			t.Errorf(`unexpected packet size %d, want at most %d`, n, statsdPacketSize)
+		}
+		lines += strings.Count(string(buf[:n]), "\n") + 1
+	}
+}
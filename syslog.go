@@ -0,0 +1,117 @@
+package cert
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Syslog severities.
+const (
+	SeverityError   = 3
+	SeverityWarning = 4
+	SeverityInfo    = 6
+)
+
+// SyslogSeverities maps Cert.Status to the severity of its syslog message.
+var SyslogSeverities = map[string]int{
+	StatusOK:       SeverityInfo,
+	StatusExpiring: SeverityWarning,
+	StatusExpired:  SeverityError,
+	StatusError:    SeverityError,
+}
+
+// syslogEnterpriseID qualifies the structured data ID. 32473 is reserved
+// for documentation by RFC 5612.
+const syslogEnterpriseID = 32473
+
+// syslogTimeLayout is an RFC 5424 TIMESTAMP, which allows at most six
+// fractional digits.
+const syslogTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// SyslogSink sends one RFC 5424 message per Cert to a syslog server.
+type SyslogSink struct {
+	// Network and Addr are passed to net.Dial, e.g. "udp" and
+	// "localhost:514". Messages on stream networks are framed by octet
+	// counting (RFC 6587).
+	Network string `json:"network"`
+	Addr    string `json:"addr"`
+	// Facility defaults to 1 (user).
+	Facility int `json:"facility,omitempty"`
+	// AppName defaults to "cert", Hostname to os.Hostname.
+	AppName  string `json:"appName,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	// Severities overrides SyslogSeverities.
+	Severities map[string]int `json:"severities,omitempty"`
+}
+
+// Send writes a message for each of certs.
+func (s *SyslogSink) Send(certs Certs) error {
+	conn, err := net.Dial(s.Network, s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stream := !strings.HasPrefix(s.Network, "udp") && s.Network != "unixgram"
+	for _, c := range certs {
+		msg := s.Format(c)
+		if stream {
+			msg = fmt.Sprintf("%d %s", len(msg), msg)
+		}
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format returns the RFC 5424 message for c.
+func (s *SyslogSink) Format(c *Cert) string {
+	facility := s.Facility
+	if facility == 0 {
+		facility = 1
+	}
+	severity, ok := s.Severities[c.Status]
+	if !ok {
+		severity = SyslogSeverities[c.Status]
+	}
+	app := s.AppName
+	if app == "" {
+		app = "cert"
+	}
+	host := s.Hostname
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	if host == "" {
+		host = "-"
+	}
+
+	var sd strings.Builder
+	fmt.Fprintf(&sd, "[cert@%d", syslogEnterpriseID)
+	for _, p := range [][2]string{
+		{"domain", c.DomainName},
+		{"status", c.Status},
+		{"issuer", c.Issuer},
+		{"notAfter", c.NotAfter},
+		{"errorKind", c.ErrorKind},
+	} {
+		if p[1] != "" {
+			fmt.Fprintf(&sd, ` %s="%s"`, p[0], sdEscaper.Replace(p[1]))
+		}
+	}
+	sd.WriteString("]")
+
+	text := fmt.Sprintf("%s %s", c.DomainName, c.Status)
+	if c.Error != "" {
+		text += ": " + c.Error
+	} else if c.NotAfter != "" {
+		text += " until " + c.NotAfter
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s\n",
+		facility*8+severity, now().UTC().Format(syslogTimeLayout), host, app, os.Getpid(), "result", sd.String(), text)
+}
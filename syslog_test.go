@@ -0,0 +1,79 @@
+package cert
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSinkFormat(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 123456789, time.UTC) }
+	defer func() { now = time.Now }()
+
+	s := &SyslogSink{Facility: 16, Hostname: "scanner"}
+	var tests = []struct {
+		cert *Cert
+		want string
+	}{
+		{
+			&Cert{DomainName: "example.com", Issuer: `CA "1"`, NotAfter: "2018-06-01 00:00:00 +0000 UTC", Status: StatusOK},
+			`<134>1 2018-01-01T00:00:00.123456Z scanner cert %d result [cert@32473 domain="example.com" status="OK" issuer="CA \"1\"" notAfter="2018-06-01 00:00:00 +0000 UTC"] example.com OK until 2018-06-01 00:00:00 +0000 UTC` + "\n",
+		},
+		{
+			&Cert{DomainName: "failed.example.com", Status: StatusError, Error: "connection refused", ErrorKind: ErrorKindRefused},
+			`<131>1 2018-01-01T00:00:00.123456Z scanner cert %d result [cert@32473 domain="failed.example.com" status="ERROR" errorKind="refused"] failed.example.com ERROR: connection refused` + "\n",
+		},
+	}
+	for _, test := range tests {
+		want := fmt.Sprintf(test.want, os.Getpid())
+		if got := s.Format(test.cert); got != want {
+			t.Errorf(`unexpected return value %q, want %q`, got, want)
+		}
+	}
+
+	s.Severities = map[string]int{StatusOK: 5}
+	if got := s.Format(tests[0].cert); !strings.HasPrefix(got, "<133>1 ") {
+		t.Errorf(`unexpected return value %q, want priority 133`, got)
+	}
+}
+
+func TestSyslogSinkSend(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			line, _ := r.ReadString('\n')
+			lines <- line
+		}
+	}()
+
+	s := &SyslogSink{Network: "tcp", Addr: l.Addr().String(), Hostname: "scanner"}
+	certs := Certs{{DomainName: "a.example.com", Status: StatusOK}, {DomainName: "b.example.com", Status: StatusExpired}}
+	if err := s.Send(certs); err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	for _, c := range certs {
+		msg := s.Format(c)
+		want := fmt.Sprintf("%d %s", len(msg), msg)
+		if got := <-lines; got != want {
+			t.Errorf(`unexpected message %q, want %q`, got, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package cert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// tableColumns are the fields of Table output.
+var tableColumns = []string{"DomainName", "Port", "IP", "Issuer", "NotAfter", "Status", "Error"}
+
+// Table renders certs as a table with one aligned row per certificate.
+func (certs Certs) Table() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	header := make([]string, len(tableColumns))
+	for i, name := range tableColumns {
+		header[i] = translate(name)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, c := range certs {
+		fmt.Fprintln(w, strings.Join([]string{c.DomainName, c.Port, c.IP, c.truncate(c.Issuer), c.NotAfter, translate(c.Status), c.truncate(c.Error)}, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
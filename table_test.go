@@ -0,0 +1,28 @@
+package cert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCertsTable(t *testing.T) {
+	certs := Certs{
+		{DomainName: "example.com", Port: "443", IP: "192.0.2.1", Issuer: "Example CA", NotAfter: "2018-01-01 00:00:00 +0000 UTC", Status: StatusOK},
+		{DomainName: "a.example.org", Port: "8443", IP: "2001:db8::1", Issuer: "CA", NotAfter: "2017-06-01 00:00:00 +0000 UTC", Status: StatusExpired},
+		{DomainName: "b.example.org", Error: "connection refused", Status: StatusError},
+	}
+	expected := `DomainName     Port  IP           Issuer      NotAfter                       Status   Error
+example.com    443   192.0.2.1    Example CA  2018-01-01 00:00:00 +0000 UTC  OK       
+a.example.org  8443  2001:db8::1  CA          2017-06-01 00:00:00 +0000 UTC  EXPIRED  
+b.example.org                                                                ERROR    connection refused
+`
+	if got := certs.Table(); got != expected {
+		t.Errorf(`unexpected return value %q, want %q`, got, expected)
+	}
+
+	defer func() { Locale = "en" }()
+	Locale = "de"
+	if got := certs[:1].Table(); !strings.HasPrefix(got, "Domainname   Port  IP         Aussteller") {
+		t.Errorf(`unexpected return value %q, want translated header`, got)
+	}
+}
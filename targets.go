@@ -0,0 +1,155 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// Target describes one endpoint of a batch scan with its own settings.
+type Target struct {
+	// Host is a host name, host:port or https:// URL.
+	Host string `json:"host"`
+	// Port overrides the port given in or implied by Host.
+	Port string `json:"port,omitempty"`
+	// ServerName is sent as SNI instead of the host name.
+	ServerName string `json:"serverName,omitempty"`
+	// ClientCert and ClientKey are PEM files presented as client
+	// certificate.
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// InsecureSkipVerify overrides Options.InsecureSkipVerify when set.
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+	// StartTLS overrides Options.StartTLS when set.
+	StartTLS string `json:"startTLS,omitempty"`
+	// Proxy is the HTTP proxy URL to connect through, or "direct". It
+	// overrides Options.Proxy.
+	Proxy string `json:"proxy,omitempty"`
+	// Labels are copied to the resulting Cert.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Pins override the pins of Options.Pins for this host.
+	Pins []string `json:"pins,omitempty"`
+}
+
+// ParseTargets parses a JSON array of Target, or an object holding the
+// array in "targets".
+func ParseTargets(data []byte) ([]Target, error) {
+	var targets []Target
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var doc struct {
+			Targets []Target `json:"targets"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		targets = doc.Targets
+	} else if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, err
+	}
+	for i, t := range targets {
+		if t.Host == "" {
+			return nil, fmt.Errorf("Target %d has no host.", i+1)
+		}
+		for _, pin := range t.Pins {
+			if err := validatePin(pin); err != nil {
+				return nil, fmt.Errorf("Target %d: %v", i+1, err)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// NewCertsFromTargets fetches the certificates of targets, applying each
+// target's settings on top of opts.
+func NewCertsFromTargets(targets []Target, opts *Options) (Certs, error) {
+	opts = opts.orDefault()
+	return scanTargets(context.Background(), targets, opts)
+}
+
+func scanTargets(ctx context.Context, targets []Target, opts *Options) (Certs, error) {
+	if len(targets) < 1 {
+		return nil, fmt.Errorf("Input at least one target.")
+	}
+	return scanContext(ctx, len(targets), opts, func(ctx context.Context, i int) *Cert {
+		return targets[i].newCert(ctx, opts)
+	})
+}
+
+func (t Target) newCert(ctx context.Context, base *Options) *Cert {
+	opts := *base
+	hostport, err := t.hostport()
+	if err != nil {
+		c := errorCert(hostport, err)
+		c.Input = t.Host
+		return c
+	}
+	if t.ServerName != "" {
+		opts.ServerName = t.ServerName
+	}
+	if t.InsecureSkipVerify != nil {
+		opts.InsecureSkipVerify = *t.InsecureSkipVerify
+	}
+	if t.StartTLS != "" {
+		opts.StartTLS = t.StartTLS
+	}
+	if t.Proxy != "" {
+		proxy, err := parseProxy(t.Proxy)
+		if err != nil {
+			host, _, _ := splitTarget(t.Host)
+			c := errorCert(host, err)
+			c.Labels = t.Labels
+			c.Input = t.Host
+			return c
+		}
+		opts.Proxy = func(string) (*url.URL, error) { return proxy, nil }
+	}
+	if len(t.Pins) > 0 {
+		host, _, _ := splitTarget(t.Host)
+		opts.Pins = map[string][]string{}
+		for h, pins := range base.Pins {
+			opts.Pins[h] = pins
+		}
+		opts.Pins[host] = t.Pins
+	}
+	if t.ClientCert != "" {
+		pair, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			host, _, _ := splitTarget(t.Host)
+			c := errorCert(host, err)
+			c.Labels = t.Labels
+			c.Input = t.Host
+			return c
+		}
+		config := &tls.Config{}
+		if opts.TLSConfig != nil {
+			config = opts.TLSConfig.Clone()
+		}
+		config.Certificates = []tls.Certificate{pair}
+		opts.TLSConfig = config
+	}
+
+	c := newCertContext(ctx, hostport, &opts)
+	c.Labels = t.Labels
+	c.Input = t.Host
+	return c
+}
+
+// hostport returns Host with Port applied, keeping its scheme, or the host name and an error
+// if Host does not parse.
+func (t Target) hostport() (string, error) {
+	if t.Port == "" {
+		return t.Host, nil
+	}
+	host, _, err := splitTarget(t.Host)
+	if err != nil {
+		return host, err
+	}
+	if scheme := targetScheme(t.Host); scheme != "" {
+		return scheme + "://" + net.JoinHostPort(host, t.Port), nil
+	}
+	return net.JoinHostPort(host, t.Port), nil
+}
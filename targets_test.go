@@ -0,0 +1,71 @@
+package cert
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	skip := true
+	want := []Target{
+		{Host: "example.com"},
+		{Host: "mail.example.com", Port: "993", ServerName: "imap.example.com", InsecureSkipVerify: &skip, Labels: map[string]string{"team": "mail"}},
+	}
+	for _, input := range []string{
+		`[{"host":"example.com"},{"host":"mail.example.com","port":"993","serverName":"imap.example.com","insecureSkipVerify":true,"labels":{"team":"mail"}}]`,
+		`{"targets":[{"host":"example.com"},{"host":"mail.example.com","port":"993","serverName":"imap.example.com","insecureSkipVerify":true,"labels":{"team":"mail"}}]}`,
+	} {
+		got, err := ParseTargets([]byte(input))
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf(`unexpected targets %+v, want %+v`, got, want)
+		}
+	}
+
+	if _, err := ParseTargets([]byte(`[{"port":"443"}]`)); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestNewCertsFromTargets(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]Options{}
+	serverCert = func(ctx context.Context, host, port string, opts *Options) (*serverInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[host+":"+port] = *opts
+		return &serverInfo{chain: stubChainCert(host)}, nil
+	}
+	defer stubCert()
+
+	skip := true
+	certs, err := NewCertsFromTargets([]Target{
+		{Host: "example.com"},
+		{Host: "mail.example.com:25", Port: "993", ServerName: "imap.example.com", InsecureSkipVerify: &skip, Labels: map[string]string{"team": "mail"}},
+		{Host: "broken.example.com", ClientCert: "no-such-file.pem", ClientKey: "no-such-file.key"},
+	}, &Options{})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	if opts, ok := got["example.com:443"]; !ok || opts.ServerName != "" || opts.InsecureSkipVerify {
+		t.Errorf(`unexpected options %+v for example.com`, opts)
+	}
+	if opts, ok := got["mail.example.com:993"]; !ok || opts.ServerName != "imap.example.com" || !opts.InsecureSkipVerify {
+		t.Errorf(`unexpected options %+v for mail.example.com`, opts)
+	}
+	if certs[1].Labels["team"] != "mail" {
+		t.Errorf(`unexpected Cert.Labels %v`, certs[1].Labels)
+	}
+	if certs[2].Error == "" || certs[2].DomainName != "broken.example.com" {
+		t.Errorf(`unexpected Cert %+v, want client certificate error`, certs[2])
+	}
+
+	if _, err := NewCertsFromTargets(nil, nil); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
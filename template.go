@@ -0,0 +1,205 @@
+package cert
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+const timeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+var funcs = template.FuncMap{
+	"toUpper":          strings.ToUpper,
+	"toLower":          strings.ToLower,
+	"date":             formatDate,
+	"until":            until,
+	"humanizeDuration": humanizeDuration,
+	"sans":             (*Cert).displaySANs,
+	"trunc":            (*Cert).truncate,
+	"unicode":          toUnicode,
+	"hostport":         hostport,
+}
+
+// RegisterFunc makes fn available as name in every template rendered by the
+// package, replacing any function already registered under that name.
+// It is not safe to call concurrently with rendering; call it during init.
+func RegisterFunc(name string, fn interface{}) {
+	funcs[name] = fn
+}
+
+// Template renders certs with the custom text/template text. Besides
+// registered functions it provides toUpper, toLower, date (e.g. {{date
+// "2006-01-02" .NotAfter}}), until (the duration from now to a time field),
+// humanizeDuration, unicode (a name with its xn-- labels decoded), hostport
+// (the domain name with its scheme, and with its port unless that is the
+// default) and the subset of the Sprig library listed in sprig.go.
+func (certs Certs) Template(text string) (string, error) {
+	var b bytes.Buffer
+	t, err := template.New("custom").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	if err := t.Execute(&b, certs); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// templateCache holds the templates parsed by TemplateFile by path.
+var templateCache = struct {
+	sync.Mutex
+	m map[string]cachedTemplate
+}{m: map[string]cachedTemplate{}}
+
+type cachedTemplate struct {
+	modTime time.Time
+	size    int64
+	t       *template.Template
+}
+
+// TemplateFile renders certs with the template in the file at path, as
+// Template does. The parsed template is cached until the file changes.
+func (certs Certs) TemplateFile(path string) (string, error) {
+	t, err := parseTemplateFile(path)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, certs); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func parseTemplateFile(path string) (*template.Template, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	templateCache.Lock()
+	defer templateCache.Unlock()
+	if c, ok := templateCache.m[path]; ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.t, nil
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.New(filepath.Base(path)).Funcs(funcs).Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+	templateCache.m[path] = cachedTemplate{fi.ModTime(), fi.Size(), t}
+	return t, nil
+}
+
+func execute(name, text string, data interface{}) string {
+	var b bytes.Buffer
+	t := template.Must(template.New(name).Funcs(funcs).Parse(text))
+	if err := t.Execute(&b, data); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeLayout, s)
+}
+
+func formatDate(layout, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := parseTime(s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+func until(s string) (time.Duration, error) {
+	t, err := parseTime(s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Sub(now()), nil
+}
+
+func humanizeDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%s%d days", sign, d/(24*time.Hour))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%s%d hours", sign, d/time.Hour)
+	case d >= 2*time.Minute:
+		return fmt.Sprintf("%s%d minutes", sign, d/time.Minute)
+	}
+	return sign + d.Round(time.Millisecond).String()
+}
+
+// displaySANs returns the SANs of c as shown in String and Markdown output,
+// decoded if UnicodeSANs is set for its scan and truncated to its MaxSANs.
+func (c *Cert) displaySANs() []string {
+	sans := c.SANs
+	if c.unicodeSANs {
+		decoded := make([]string, len(sans))
+		for i, san := range sans {
+			decoded[i] = san
+			if u := toUnicode(san); u != san {
+				decoded[i] = u + " (" + san + ")"
+			}
+		}
+		sans = decoded
+	}
+	return truncateSANs(sans, c.maxSANs)
+}
+
+// Display returns copies of certs that String, Markdown and Table render
+// with the display options of opts, MaxSANs, UnicodeSANs and MaxWidth, e.g.
+// for results read with ParseJSON rather than scanned with opts.
+func (certs Certs) Display(opts *Options) Certs {
+	displayed := make(Certs, len(certs))
+	for i, cert := range certs {
+		c := *cert
+		c.setDisplay(opts.orDefault())
+		displayed[i] = &c
+	}
+	return displayed
+}
+
+// setDisplay sets the display options of opts on c.
+func (c *Cert) setDisplay(opts *Options) {
+	c.maxSANs = opts.MaxSANs
+	c.unicodeSANs = opts.UnicodeSANs
+	c.maxWidth = opts.MaxWidth
+}
+
+// truncate shortens s to the MaxWidth of the scan of c, ending in an
+// ellipsis.
+func (c *Cert) truncate(s string) string {
+	if c.maxWidth <= 0 || utf8.RuneCountInString(s) <= c.maxWidth {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:c.maxWidth-1]) + "…"
+}
+
+// truncateSANs returns the first max of sans followed by a marker counting
+// the rest, or all of sans if max is not positive.
+func truncateSANs(sans []string, max int) []string {
+	if max <= 0 || len(sans) <= max {
+		return sans
+	}
+	out := append([]string(nil), sans[:max]...)
+	return append(out, fmt.Sprintf("(+%d more)", len(sans)-max))
+}
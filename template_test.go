@@ -0,0 +1,231 @@
+package cert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertsTemplate(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "example.com", NotAfter: "2018-01-31 09:00:00 +0900 JST"},
+	}
+
+	got, err := certs.Template(`{{range .}}{{toUpper .DomainName}} {{date "2006-01-02" .NotAfter}} {{humanizeDuration (until .NotAfter)}}{{end}}`)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if got != "EXAMPLE.COM 2018-01-31 30 days" {
+		t.Errorf(`unexpected return value %q, want %q`, got, "EXAMPLE.COM 2018-01-31 30 days")
+	}
+}
+
+func TestCertsTemplateError(t *testing.T) {
+	if _, err := (Certs{}).Template(`{{range .}`); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	RegisterFunc("shout", func(s string) string { return s + "!" })
+	defer delete(funcs, "shout")
+
+	got, err := Certs{{DomainName: "example.com"}}.Template(`{{range .}}{{shout .DomainName}}{{end}}`)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if got != "example.com!" {
+		t.Errorf(`unexpected return value %q, want %q`, got, "example.com!")
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	var tests = []struct {
+		input time.Duration
+		want  string
+	}{
+		{72 * time.Hour, "3 days"},
+		{-72 * time.Hour, "-3 days"},
+		{5 * time.Hour, "5 hours"},
+		{10 * time.Minute, "10 minutes"},
+		{1500 * time.Microsecond, "2ms"},
+	}
+	for _, test := range tests {
+		if got := humanizeDuration(test.input); got != test.want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestFormatDateOfRenderedTime(t *testing.T) {
+	tm := time.Date(2017, time.January, 1, 12, 30, 0, 0, time.Local)
+	got, err := formatDate("2006-01-02 15:04", tm.String())
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if !strings.HasPrefix(got, "2017-01-01 12:30") {
+		t.Errorf(`unexpected return value %q, want %q`, got, "2017-01-01 12:30")
+	}
+}
+
+func TestMaxSANs(t *testing.T) {
+	certs := Certs{{DomainName: "example.com", SANs: []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"}}}
+	if got := certs.String(); !strings.Contains(got, "d.example.com]\n") {
+		t.Errorf(`unexpected return value %q, want every SAN`, got)
+	}
+
+	certs = certs.Display(&Options{MaxSANs: 2})
+	if got := certs.String(); !strings.Contains(got, "[a.example.com b.example.com (+2 more)]\n") {
+		t.Errorf(`unexpected return value %q, want truncated SANs`, got)
+	}
+	if got := certs.Markdown(); !strings.Contains(got, "| a.example.com<br/>b.example.com<br/>(+2 more)<br/> |") {
+		t.Errorf(`unexpected return value %q, want truncated SANs`, got)
+	}
+	if got := certs.WithFields("SANs").String(); got != "SANs: [a.example.com b.example.com (+2 more)]\n\n\n" {
+		t.Errorf(`unexpected return value %q, want truncated SANs`, got)
+	}
+	if got := string(certs.JSON()); !strings.Contains(got, `"d.example.com"`) {
+		t.Errorf(`unexpected return value %q, want every SAN`, got)
+	}
+
+	if got := truncateSANs(certs[0].SANs, 4); len(got) != 4 {
+		t.Errorf(`unexpected SANs %q, want all 4`, got)
+	}
+}
+
+func TestMaxSANsOption(t *testing.T) {
+	stubCert()
+	defer stubCert()
+
+	c := NewCertWithOptions("example.com", &Options{MaxSANs: 1})
+	if got := c.String(); !strings.Contains(got, "[example.com (+1 more)]\n") {
+		t.Errorf(`unexpected return value %q, want truncated SANs`, got)
+	}
+	c = NewCertWithOptions("example.com", &Options{})
+	if got := c.String(); !strings.Contains(got, "[example.com www.example.com]\n") {
+		t.Errorf(`unexpected return value %q, want every SAN`, got)
+	}
+}
+
+func TestUnicodeSANs(t *testing.T) {
+	certs := Certs{{DomainName: "xn--mnchen-3ya.example", SANs: []string{"xn--mnchen-3ya.example", "*.xn--mnchen-3ya.example", "example.com", "xn--bad!.example"}}}
+
+	if got := certs.String(); !strings.Contains(got, "[xn--mnchen-3ya.example *.xn--mnchen-3ya.example") {
+		t.Errorf(`unexpected return value %q, want A-labels only`, got)
+	}
+
+	certs = certs.Display(&Options{UnicodeSANs: true})
+	want := "[münchen.example (xn--mnchen-3ya.example) *.münchen.example (*.xn--mnchen-3ya.example) example.com xn--bad!.example]"
+	if got := certs.String(); !strings.Contains(got, want) {
+		t.Errorf(`unexpected return value %q, want %q`, got, want)
+	}
+	if got := certs.Markdown(); !strings.Contains(got, "münchen.example (xn--mnchen-3ya.example)<br/>") {
+		t.Errorf(`unexpected return value %q, want decoded SANs`, got)
+	}
+	if got := string(certs.JSON()); strings.Contains(got, "münchen") {
+		t.Errorf(`unexpected return value %q, want A-labels only`, got)
+	}
+	if got, _ := certs.Template(`{{range .}}{{unicode .DomainName}}{{end}}`); got != "münchen.example" {
+		t.Errorf(`unexpected return value %q, want %q`, got, "münchen.example")
+	}
+}
+
+func TestUnicodeSANsOption(t *testing.T) {
+	stubCert()
+	defer stubCert()
+
+	c := NewCertWithOptions("xn--mnchen-3ya.example", &Options{UnicodeSANs: true})
+	if got := c.String(); !strings.Contains(got, "[münchen.example (xn--mnchen-3ya.example)") {
+		t.Errorf(`unexpected return value %q, want decoded SANs`, got)
+	}
+	c = NewCertWithOptions("xn--mnchen-3ya.example", &Options{})
+	if got := c.String(); strings.Contains(got, "münchen") {
+		t.Errorf(`unexpected return value %q, want A-labels only`, got)
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	certs := Certs{{
+		DomainName: "example.com",
+		Issuer:     "Example Organization Validation Secure Server CA",
+		CommonName: "example.com",
+		SANs:       []string{"a-very-long-subdomain.example.com", "b.example.com"},
+		Error:      "",
+	}}
+
+	certs = certs.Display(&Options{MaxWidth: 20})
+	got := certs.String()
+	for _, want := range []string{"Issuer:     Example Organizatio…\n", "CommonName: example.com\n", "SANs:       [a-very-long-subdom…\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf(`unexpected return value %q, want %q`, got, want)
+		}
+	}
+	if got := certs.Markdown(); !strings.Contains(got, "| Example Organizatio… |") || !strings.Contains(got, "a-very-long-subdoma…<br/>b.example.com<br/>") {
+		t.Errorf(`unexpected return value %q, want truncated issuer and SANs`, got)
+	}
+	if got := certs.WithFields("Issuer").String(); got != "Issuer: Example Organizatio…\n\n\n" {
+		t.Errorf(`unexpected return value %q, want truncated issuer`, got)
+	}
+	if got := certs.Table(); !strings.Contains(got, "Example Organizatio…") {
+		t.Errorf(`unexpected return value %q, want truncated issuer`, got)
+	}
+	if got := string(certs.JSON()); !strings.Contains(got, certs[0].Issuer) {
+		t.Errorf(`unexpected return value %q, want full issuer`, got)
+	}
+
+	if got := certs[0].truncate("ü" + strings.Repeat("x", 19)); got != "ü"+strings.Repeat("x", 19) {
+		t.Errorf(`unexpected return value %q, want it unchanged`, got)
+	}
+}
+
+func TestMaxWidthOption(t *testing.T) {
+	stubCert()
+	defer stubCert()
+
+	c := NewCertWithOptions("a-very-long-subdomain.example.com", &Options{MaxWidth: 10})
+	if got := c.String(); !strings.Contains(got, "CommonName: a-very-lo…\n") {
+		t.Errorf(`unexpected return value %q, want truncated common name`, got)
+	}
+	if got := (Certs{c}).Markdown(); !strings.Contains(got, "| a-very-lo…<br/>www.a-ver…<br/> |") {
+		t.Errorf(`unexpected return value %q, want truncated SANs`, got)
+	}
+	if got := (Certs{c}).Table(); !strings.Contains(got, "CA for te…") {
+		t.Errorf(`unexpected return value %q, want truncated issuer`, got)
+	}
+}
+
+func TestCertsTemplateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range .}}{{.DomainName}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	certs := Certs{{DomainName: "example.com"}}
+
+	got, err := certs.TemplateFile(path)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if got != "example.com" {
+		t.Errorf(`unexpected return value %q, want %q`, got, "example.com")
+	}
+	cached, _ := parseTemplateFile(path)
+	if again, _ := parseTemplateFile(path); again != cached {
+		t.Error(`unexpected reparse of unchanged template`)
+	}
+
+	if err := os.WriteFile(path, []byte(`{{range .}}{{toUpper .DomainName}}!{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := certs.TemplateFile(path); got != "EXAMPLE.COM!" {
+		t.Errorf(`unexpected return value %q after change, want %q`, got, "EXAMPLE.COM!")
+	}
+
+	if _, err := certs.TemplateFile(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
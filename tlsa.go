@@ -0,0 +1,51 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+)
+
+// TLSA renders a DANE TLSA resource record for each certificate.
+// usage is 0-3 (PKIX-TA, PKIX-EE, DANE-TA, DANE-EE), selector 0 (full
+// certificate) or 1 (SubjectPublicKeyInfo) and matchingType 0 (exact),
+// 1 (SHA-256) or 2 (SHA-512). Trust anchor usages use the topmost
+// certificate the server presents. Hosts which could not be fetched are
+// skipped.
+func (certs Certs) TLSA(usage, selector, matchingType uint8) (string, error) {
+	if usage > 3 || selector > 1 || matchingType > 2 {
+		return "", fmt.Errorf("Invalid TLSA parameters %d %d %d.", usage, selector, matchingType)
+	}
+
+	var b bytes.Buffer
+	for _, cert := range certs {
+		if len(cert.chain) == 0 {
+			continue
+		}
+		c := cert.chain[0]
+		if usage == 0 || usage == 2 {
+			c = cert.chain[len(cert.chain)-1]
+		}
+
+		data := c.Raw
+		if selector == 1 {
+			data = c.RawSubjectPublicKeyInfo
+		}
+		switch matchingType {
+		case 1:
+			sum := sha256.Sum256(data)
+			data = sum[:]
+		case 2:
+			sum := sha512.Sum512(data)
+			data = sum[:]
+		}
+
+		port := cert.Port
+		if port == "" {
+			port = defaultPort
+		}
+		fmt.Fprintf(&b, "_%s._tcp.%s. IN TLSA %d %d %d %x\n", port, cert.DomainName, usage, selector, matchingType, data)
+	}
+	return b.String(), nil
+}
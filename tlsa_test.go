@@ -0,0 +1,39 @@
+package cert
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"testing"
+)
+
+func TestCertsTLSA(t *testing.T) {
+	leaf := &x509.Certificate{Raw: []byte("leaf"), RawSubjectPublicKeyInfo: []byte("leaf key")}
+	ca := &x509.Certificate{Raw: []byte("ca"), RawSubjectPublicKeyInfo: []byte("ca key")}
+	certs := Certs{
+		{DomainName: "mail.example.com", Port: "25", chain: []*x509.Certificate{leaf, ca}},
+		{DomainName: "example.com", chain: []*x509.Certificate{leaf}},
+		{DomainName: "example.net", Error: "connection refused"},
+	}
+
+	var tests = []struct {
+		usage, selector, matchingType uint8
+		want                          string
+	}{
+		{3, 1, 1, fmt.Sprintf("_25._tcp.mail.example.com. IN TLSA 3 1 1 %x\n_443._tcp.example.com. IN TLSA 3 1 1 %x\n", sha256.Sum256([]byte("leaf key")), sha256.Sum256([]byte("leaf key")))},
+		{2, 0, 0, fmt.Sprintf("_25._tcp.mail.example.com. IN TLSA 2 0 0 %x\n_443._tcp.example.com. IN TLSA 2 0 0 %x\n", "ca", "leaf")},
+	}
+	for _, test := range tests {
+		got, err := certs.TLSA(test.usage, test.selector, test.matchingType)
+		if err != nil {
+			t.Fatalf(`unexpected err %s, want nil`, err.Error())
+		}
+		if got != test.want {
+			t.Errorf("TLSA(%d, %d, %d) = %q, want %q", test.usage, test.selector, test.matchingType, got, test.want)
+		}
+	}
+
+	if _, err := certs.TLSA(4, 1, 1); err == nil {
+		t.Error(`unexpected nil, want error`)
+	}
+}
@@ -0,0 +1,48 @@
+package cert
+
+import (
+	"context"
+)
+
+// Tracer starts spans around the phases of a scan. It is small enough to be
+// adapted to an OpenTelemetry trace.Tracer in a few lines:
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, cert.Span) {
+//		var kvs []attribute.KeyValue
+//		for k, v := range attrs {
+//			kvs = append(kvs, attribute.String(k, v))
+//		}
+//		ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(kvs...))
+//		return ctx, otelSpan{span}
+//	}
+//
+// Spans are named cert.scan, cert.target, cert.resolve, cert.dial and
+// cert.handshake. With Options.FollowRedirects each request on the way is a
+// cert.redirect span with its own cert.resolve, cert.dial and
+// cert.handshake spans.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span)
+}
+
+// Span is a traced operation started by a Tracer.
+type Span interface {
+	// End finishes the span, recording err if it is not nil.
+	End(err error)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
+
+// startSpan starts a span with opts.Tracer, if any. kv are attribute name
+// and value pairs.
+func startSpan(ctx context.Context, opts *Options, name string, kv ...string) (context.Context, Span) {
+	if opts == nil || opts.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	attrs := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		attrs[kv[i]] = kv[i+1]
+	}
+	return opts.Tracer.Start(ctx, name, attrs)
+}
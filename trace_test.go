@@ -0,0 +1,123 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testSpan struct {
+	name, parent string
+	attrs        map[string]string
+	err          error
+	ended        bool
+	tracer       *testTracer
+}
+
+func (s *testSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err, s.ended = err, true
+}
+
+type spanKey struct{}
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent, _ := ctx.Value(spanKey{}).(string)
+	s := &testSpan{name: name, parent: parent, attrs: attrs, tracer: t}
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, name), s
+}
+
+func TestTracer(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	ca := newTestCA(t, "Trace CA", nil)
+	leaf := newTestCert(t, &x509.Certificate{DNSNames: []string{"localhost"}}, ca)
+	host, port := startTLSServer(t, &tls.Config{Certificates: []tls.Certificate{{
+		Certificate: [][]byte{leaf.cert.Raw},
+		PrivateKey:  leaf.key,
+	}}})
+
+	tracer := &testTracer{}
+	_, err := NewCertsWithOptions([]string{host + ":" + port, "localhost:1"}, &Options{InsecureSkipVerify: true, Tracer: tracer})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	var got []string
+	for _, s := range tracer.spans {
+		if !s.ended {
+			t.Errorf(`span %s not ended`, s.name)
+		}
+		failed := ""
+		if s.err != nil {
+			failed = " failed"
+		}
+		got = append(got, s.parent+">"+s.name+" "+s.attrs["port"]+failed)
+	}
+	sort.Strings(got)
+	want := []string{
+		">cert.scan ",
+		"cert.scan>cert.target 1 failed",
+		"cert.scan>cert.target " + port,
+		"cert.target>cert.dial 1 failed",
+		"cert.target>cert.dial " + port,
+		"cert.target>cert.handshake " + port,
+		"cert.target>cert.resolve ",
+		"cert.target>cert.resolve ",
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf(`unexpected spans:
+%s
+want:
+%s`, strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTracerRedirects(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	apex := startRedirectServer(t, "")
+	www := startRedirectServer(t, apex.URL+"/")
+
+	tracer := &testTracer{}
+	_, err := NewCertsWithOptions([]string{www.URL + "/"}, &Options{InsecureSkipVerify: true, FollowRedirects: true, Tracer: tracer})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	count := map[string]int{}
+	for _, s := range tracer.spans {
+		if !s.ended {
+			t.Errorf(`span %s not ended`, s.name)
+		}
+		count[s.parent+">"+s.name]++
+	}
+	for span, want := range map[string]int{
+		">cert.scan":                   1,
+		"cert.scan>cert.redirect":      2,
+		"cert.redirect>cert.dial":      2,
+		"cert.redirect>cert.handshake": 2,
+		"cert.scan>cert.target":        2,
+	} {
+		if count[span] != want {
+			t.Errorf(`unexpected %d spans %s, want %d`, count[span], span, want)
+		}
+	}
+}
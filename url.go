@@ -0,0 +1,222 @@
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const maxRedirects = 10
+
+// NewCertsFromURL performs a GET request to rawurl, follows redirects and
+// returns a Cert for every distinct HTTPS host:port visited on the way,
+// in the order they were visited.
+func NewCertsFromURL(rawurl string, opts *Options) (Certs, error) {
+	opts = opts.orDefault()
+	transport := redirectTransport(opts)
+	defer transport.CloseIdleConnections()
+	_, targets, err := redirectChain(context.Background(), transport, rawurl, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No HTTPS hosts found in redirect chain of %s.", rawurl)
+	}
+	return NewCertsWithOptions(targets, opts)
+}
+
+// newCertsFollowingRedirects scans s and, for every URL in s, the HTTPS
+// hosts its redirect chain passes through. Those implicit targets follow
+// the explicit ones and name the URL they were found from in Via. Chains
+// are followed concurrently within the limit of opts.Concurrency.
+func newCertsFollowingRedirects(ctx context.Context, s []string, opts *Options) (Certs, error) {
+	seen := map[string]bool{}
+	for _, target := range s {
+		if host, port, err := splitTarget(target); err == nil {
+			seen[net.JoinHostPort(host, port)] = true
+		}
+	}
+
+	limit := tokens
+	if opts.Concurrency > 0 {
+		limit = make(chan struct{}, opts.Concurrency)
+	}
+	transport := redirectTransport(opts)
+	defer transport.CloseIdleConnections()
+	redirects := make([][]string, len(s))
+	chainHosts := make([][]string, len(s))
+	var wg sync.WaitGroup
+	for i, target := range s {
+		if !strings.Contains(target, "://") {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			urls, hosts, err := redirectChain(ctx, transport, target, opts)
+			if err != nil {
+				opts.logDebug("following redirects failed", "url", target, "err", err)
+				return
+			}
+			redirects[i], chainHosts[i] = urls, hosts
+		}(i, target)
+	}
+	wg.Wait()
+
+	targets := append([]string{}, s...)
+	var via []string
+	for i, target := range s {
+		for _, hostport := range chainHosts[i] {
+			if !seen[hostport] {
+				seen[hostport] = true
+				targets = append(targets, hostport)
+				via = append(via, target)
+			}
+		}
+	}
+
+	certs, err := newCerts(ctx, targets, opts)
+	for i := range s {
+		certs[i].Redirects = redirects[i]
+	}
+	for i, v := range via {
+		certs[len(s)+i].Via = v
+	}
+	return certs, err
+}
+
+// redirectTransport returns the transport to follow redirect chains with,
+// connecting like scans do. Callers close its idle connections when done.
+func redirectTransport(opts *Options) *http.Transport {
+	config := opts.tlsConfig("")
+	config.ServerName = ""
+	return &http.Transport{
+		Proxy:           opts.httpProxy,
+		DialContext:     opts.dialContext,
+		TLSClientConfig: config,
+	}
+}
+
+// redirectChain returns every URL visited when requesting rawurl through
+// transport and the distinct HTTPS host:port pairs among them. The chain is
+// bounded by opts.Timeout and each request is traced as a cert.redirect
+// span.
+func redirectChain(ctx context.Context, transport http.RoundTripper, rawurl string, opts *Options) ([]string, []string, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	var urls, targets []string
+	seen := map[string]bool{}
+	visit := func(u *url.URL) {
+		urls = append(urls, u.String())
+		if u.Scheme != "https" {
+			return
+		}
+		port := u.Port()
+		if port == "" {
+			port = defaultPort
+		}
+		hostport := net.JoinHostPort(u.Hostname(), port)
+		if !seen[hostport] {
+			seen[hostport] = true
+			targets = append(targets, hostport)
+		}
+	}
+
+	client := &http.Client{
+		Transport: tracedTransport{RoundTripper: transport, opts: opts},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			visit(req.URL)
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawurl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	visit(req.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return urls, targets, nil
+}
+
+// tracedTransport starts a cert.redirect span around each request, with
+// cert.resolve, cert.dial and cert.handshake spans of its connection below
+// it as scans of targets have.
+type tracedTransport struct {
+	http.RoundTripper
+	opts *Options
+}
+
+func (t tracedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := startSpan(req.Context(), t.opts, "cert.redirect", "url", req.URL.String())
+	if t.opts.Tracer != nil {
+		ctx = httptrace.WithClientTrace(ctx, clientTrace(ctx, t.opts))
+	}
+	resp, err := t.RoundTripper.RoundTrip(req.WithContext(ctx))
+	span.End(err)
+	return resp, err
+}
+
+// clientTrace returns the hooks that start and end the spans of a
+// connection under ctx. Addresses are dialed in parallel, so dial spans are
+// kept by address.
+func clientTrace(ctx context.Context, opts *Options) *httptrace.ClientTrace {
+	var mu sync.Mutex
+	spans := map[string]Span{}
+	start := func(key, name string, kv ...string) {
+		_, span := startSpan(ctx, opts, name, kv...)
+		mu.Lock()
+		spans[key] = span
+		mu.Unlock()
+	}
+	end := func(key string, err error) {
+		mu.Lock()
+		span, ok := spans[key]
+		delete(spans, key)
+		mu.Unlock()
+		if ok {
+			span.End(err)
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			start("resolve", "cert.resolve", "host", info.Host)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			end("resolve", info.Err)
+		},
+		ConnectStart: func(network, addr string) {
+			host, port, _ := net.SplitHostPort(addr)
+			start("dial "+addr, "cert.dial", "host", host, "port", port)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			end("dial "+addr, err)
+		},
+		TLSHandshakeStart: func() {
+			start("handshake", "cert.handshake")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			end("handshake", err)
+		},
+	}
+}
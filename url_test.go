@@ -0,0 +1,118 @@
+package cert
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startRedirectServer(t *testing.T, location string) *httptest.Server {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if location != "" {
+			http.Redirect(w, r, location, http.StatusMovedPermanently)
+		}
+	}))
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestNewCertsFromURL(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	cdn := startRedirectServer(t, "")
+	apex := startRedirectServer(t, cdn.URL+"/landing")
+	www := startRedirectServer(t, apex.URL+"/")
+	again := startRedirectServer(t, www.URL+"/")
+
+	certs, err := NewCertsFromURL(again.URL+"/", &Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+
+	if len(certs) != 4 {
+		t.Fatalf(`unexpected length %d, want %d`, len(certs), 4)
+	}
+	for _, c := range certs {
+		if c.Error != "" {
+			t.Errorf(`unexpected Cert.Error %q, want %q`, c.Error, "")
+		}
+	}
+}
+
+func TestRedirectChainDeduplicates(t *testing.T) {
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, s.URL+"/end", http.StatusFound)
+		}
+	}))
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	defer s.Close()
+
+	opts := &Options{InsecureSkipVerify: true}
+	urls, targets, err := redirectChain(context.Background(), redirectTransport(opts), s.URL+"/", opts)
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	want := []string{strings.TrimPrefix(s.URL, "https://")}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf(`unexpected targets %v, want %v`, targets, want)
+	}
+	if !reflect.DeepEqual(urls, []string{s.URL + "/", s.URL + "/end"}) {
+		t.Errorf(`unexpected urls %v, want %v`, urls, []string{s.URL + "/", s.URL + "/end"})
+	}
+}
+
+func TestRedirectChainTimeout(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	defer s.Close()
+
+	start := time.Now()
+	if _, err := NewCertsFromURL(s.URL+"/", &Options{InsecureSkipVerify: true, Timeout: 100 * time.Millisecond}); err == nil {
+		t.Error(`unexpected nil, want timeout`)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf(`unexpected wait of %s, want the timeout`, elapsed)
+	}
+}
+
+func TestNewCertsFollowingRedirects(t *testing.T) {
+	serverCert = realServerCert
+	defer stubCert()
+
+	apex := startRedirectServer(t, "")
+	www := startRedirectServer(t, apex.URL+"/")
+	other := startRedirectServer(t, "")
+
+	input := []string{www.URL + "/", strings.TrimPrefix(other.URL, "https://")}
+	certs, err := NewCertsWithOptions(input, &Options{InsecureSkipVerify: true, FollowRedirects: true})
+	if err != nil {
+		t.Fatalf(`unexpected err %s, want nil`, err.Error())
+	}
+	if len(certs) != 3 {
+		t.Fatalf(`unexpected length %d, want %d`, len(certs), 3)
+	}
+	if !reflect.DeepEqual(certs[0].Redirects, []string{www.URL + "/", apex.URL + "/"}) {
+		t.Errorf(`unexpected Cert.Redirects %v`, certs[0].Redirects)
+	}
+	if certs[1].Redirects != nil || certs[1].Via != "" {
+		t.Errorf(`unexpected redirect data on a plain target`)
+	}
+	if certs[2].Via != www.URL+"/" || certs[2].Error != "" {
+		t.Errorf(`unexpected implicit Cert %+v`, certs[2])
+	}
+}
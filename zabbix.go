@@ -0,0 +1,51 @@
+package cert
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// zabbixRow is one discovered certificate. The {#...} keys are LLD macros
+// for item and trigger prototypes; the others are values for dependent
+// items to extract with JSONPath.
+type zabbixRow struct {
+	Domain        string `json:"{#DOMAIN}"`
+	Port          string `json:"{#PORT}"`
+	Target        string `json:"{#TARGET}"`
+	Issuer        string `json:"{#ISSUER}"`
+	NotAfter      *int64 `json:"notAfter"`
+	DaysRemaining *int64 `json:"daysRemaining"`
+	Status        string `json:"status"`
+	Error         string `json:"error"`
+}
+
+// Zabbix returns certs as Zabbix low-level discovery JSON, one row per
+// certificate with the macros {#DOMAIN}, {#PORT}, {#TARGET} and {#ISSUER},
+// and its notAfter as Unix time, daysRemaining, status and error. Failed
+// certs have null notAfter and daysRemaining.
+func (certs Certs) Zabbix() []byte {
+	doc := struct {
+		Data []zabbixRow `json:"data"`
+	}{Data: []zabbixRow{}}
+	for _, c := range certs {
+		row := zabbixRow{
+			Domain: c.DomainName,
+			Port:   c.Port,
+			Target: hostport(c),
+			Issuer: c.Issuer,
+			Status: c.Status,
+			Error:  c.Error,
+		}
+		if notAfter, ok := c.expiry(); ok && c.Error == "" {
+			unix := notAfter.Unix()
+			days := int64(notAfter.Sub(now()) / (24 * time.Hour))
+			row.NotAfter, row.DaysRemaining = &unix, &days
+		}
+		doc.Data = append(doc.Data, row)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -0,0 +1,27 @@
+package cert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCertsZabbix(t *testing.T) {
+	now = func() time.Time { return time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	certs := Certs{
+		{DomainName: "example.com", Port: "443", Issuer: "Example CA", NotAfter: "2018-01-31 00:00:00 +0000 UTC", Status: StatusOK},
+		{DomainName: "mail.example.com", Port: "993", Scheme: "imaps", Status: StatusError, Error: "connection refused"},
+	}
+	want := `{"data":[` +
+		`{"{#DOMAIN}":"example.com","{#PORT}":"443","{#TARGET}":"example.com","{#ISSUER}":"Example CA","notAfter":1517356800,"daysRemaining":30,"status":"OK","error":""},` +
+		`{"{#DOMAIN}":"mail.example.com","{#PORT}":"993","{#TARGET}":"imaps://mail.example.com","{#ISSUER}":"","notAfter":null,"daysRemaining":null,"status":"ERROR","error":"connection refused"}` +
+		`]}`
+	if got := string(certs.Zabbix()); got != want {
+		t.Errorf(`unexpected return value %s, want %s`, got, want)
+	}
+
+	if got := string(Certs{}.Zabbix()); got != `{"data":[]}` {
+		t.Errorf(`unexpected return value %s, want empty data`, got)
+	}
+}